@@ -20,13 +20,25 @@ func removeAccents(s string) string {
 }
 
 // detectASCIIWords uses regex with word boundaries for ASCII words
-func (p *Plugin) detectASCIIWords(text string, asciiWords []string) []string {
-	regex := p.getASCIIWordsRegex()
+func (p *Plugin) detectASCIIWords(text string, asciiWords []string, terms *termSet) []detection {
+	regex := terms.asciiWordsRegex
 	if regex == nil {
-		return []string{}
+		return []detection{}
 	}
 
-	return regex.FindAllString(removeAccents(text), -1)
+	normalized := normalizeRunes(text, func(r rune) string { return removeAccents(string(r)) })
+
+	var detected []detection
+	for _, match := range regex.FindAllStringIndex(normalized.text, -1) {
+		if match[1] > match[0] {
+			start, end := normalized.originalSpan(match[0], match[1])
+			d := newDetection(text, start, end)
+			d.Strategy = "regex"
+			detected = append(detected, d)
+		}
+	}
+
+	return detected
 }
 
 // separateASCIIAndJapanese separates a word list into ASCII words and Japanese words
@@ -133,3 +133,18 @@ func TestMessageWillBePosted(t *testing.T) {
 		assert.Equal(t, expected, rpost, "Bot messages should not be filtered when ExcludeBots is true")
 	})
 }
+
+func TestBotNotificationsAreNotFiltered(t *testing.T) {
+	p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: "abc", ExcludeBots: false})
+	p.botID = "bot-id"
+
+	in := &model.Post{UserId: "bot-id", Message: "Your message contained profanity (abc) and was censored."}
+	rpost, s := p.MessageWillBePosted(&plugin.Context{}, in)
+	assert.Empty(t, s)
+	assert.Equal(t, "Your message contained profanity (abc) and was censored.", rpost.Message)
+
+	in = &model.Post{UserId: "user-id", Message: "abc"}
+	rpost, s = p.MessageWillBePosted(&plugin.Context{}, in)
+	assert.Empty(t, s)
+	assert.Equal(t, "***", rpost.Message)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// expiringCache remembers the values looked up through the API for a while, so that they are not
+// looked up for every post. The number of entries is bounded.
+type expiringCache[V any] struct {
+	lock       sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]expiringCacheEntry[V]
+}
+
+// expiringCacheEntry is a cached value and the time it expires at
+type expiringCacheEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// newExpiringCache creates an empty cache whose entries expire after the given time
+func newExpiringCache[V any](ttl time.Duration, maxEntries int) *expiringCache[V] {
+	return &expiringCache[V]{ttl: ttl, maxEntries: maxEntries, entries: map[string]expiringCacheEntry[V]{}}
+}
+
+// get returns the cached value of a key, if it has not expired
+func (c *expiringCache[V]) get(key string) (value V, ok bool) {
+	if c == nil {
+		return value, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return value, false
+	}
+	return entry.value, true
+}
+
+// set caches the value of a key. Once the cache is full, the expired entries are pruned and, if it
+// is still full, the entry that expires first is evicted.
+func (c *expiringCache[V]) set(key string, value V) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		oldestKey, oldest := "", time.Time{}
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			} else if oldestKey == "" || entry.expires.Before(oldest) {
+				oldestKey, oldest = k, entry.expires
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			delete(c.entries, oldestKey)
+		}
+	}
+
+	c.entries[key] = expiringCacheEntry[V]{value: value, expires: now.Add(c.ttl)}
+}
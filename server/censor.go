@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"unicode"
+)
+
+// Censor styles, selecting how a detected word is censored
+const (
+	// censorStyleMask replaces every character with the censor character, e.g. "****"
+	censorStyleMask = "mask"
+	// censorStyleFirstLetter keeps the first letter, e.g. "f***"
+	censorStyleFirstLetter = "first-letter"
+	// censorStyleFirstLastLetter keeps the first and last letters, e.g. "f**k"
+	censorStyleFirstLastLetter = "first-last-letter"
+	// censorStyleFixed replaces the word with a fixed number of censor characters, hiding its length
+	censorStyleFixed = "fixed"
+	// censorStyleGrawlix replaces every character with a random symbol, e.g. "@#$%"
+	censorStyleGrawlix = "grawlix"
+	// censorStyleStrikethrough keeps the word but strikes it through, e.g. "~~fuck~~"
+	censorStyleStrikethrough = "strikethrough"
+	// censorStylePlaceholder replaces the word with the placeholder, e.g. "[censored]"
+	censorStylePlaceholder = "placeholder"
+)
+
+const (
+	// censorFixedLength is the number of censor characters of the fixed style
+	censorFixedLength = 4
+	// defaultCensorPlaceholder is the placeholder used when none is configured
+	defaultCensorPlaceholder = "[censored]"
+	// grawlixSymbols are the symbols of the grawlix style
+	grawlixSymbols = "@#$%&!"
+	// markdownSpecialCharacters are escaped with a backslash so that censored words never turn
+	// into markdown formatting
+	markdownSpecialCharacters = "\\`*_~[]<>#|"
+)
+
+var censorStyles = map[string]bool{
+	censorStyleMask:            true,
+	censorStyleFirstLetter:     true,
+	censorStyleFirstLastLetter: true,
+	censorStyleFixed:           true,
+	censorStyleGrawlix:         true,
+	censorStyleStrikethrough:   true,
+	censorStylePlaceholder:     true,
+}
+
+// parseCensorStyle validates a censor style, defaulting to the full mask
+func parseCensorStyle(style string) (string, error) {
+	style = strings.ToLower(strings.TrimSpace(style))
+	if style == "" {
+		return censorStyleMask, nil
+	}
+	if !censorStyles[style] {
+		return "", fmt.Errorf("unsupported censor style %q", style)
+	}
+	return style, nil
+}
+
+// parseCategoryStyles parses the censor style of each category, separated by commas, e.g.
+// "slurs=placeholder, mild=first-letter"
+func parseCategoryStyles(setting string) (map[string]string, error) {
+	styles := map[string]string{}
+	for _, entry := range splitWordList(setting) {
+		category, style, ok := strings.Cut(entry, "=")
+		category = strings.ToLower(strings.TrimSpace(category))
+		if !ok || category == "" {
+			return nil, fmt.Errorf("invalid category censor style %q, expected category=style", entry)
+		}
+		parsed, err := parseCensorStyle(style)
+		if err != nil {
+			return nil, err
+		}
+		styles[category] = parsed
+	}
+	return styles, nil
+}
+
+// censorer censors detected words in the configured styles
+type censorer struct {
+	style          string
+	character      string
+	placeholder    string
+	categoryStyles map[string]string
+	escapeMarkdown bool
+}
+
+// newCensorer creates a censorer for the configuration
+func (p *Plugin) newCensorer(config *configuration) censorer {
+	style, err := parseCensorStyle(config.CensorStyle)
+	if err != nil {
+		style = censorStyleMask
+	}
+	placeholder := config.CensorPlaceholder
+	if placeholder == "" {
+		placeholder = defaultCensorPlaceholder
+	}
+	return censorer{
+		style:          style,
+		character:      config.CensorCharacter,
+		placeholder:    placeholder,
+		categoryStyles: p.getCensorCategoryStyles(),
+		escapeMarkdown: config.CensorEscapeMarkdown,
+	}
+}
+
+// styleOf returns the style a detection is censored in: the style of its category if one is
+// configured, and the installation style otherwise
+func (c censorer) styleOf(d detection) string {
+	if style, ok := c.categoryStyles[strings.ToLower(d.Category)]; ok {
+		return style
+	}
+	return c.style
+}
+
+// censor returns the censored form of the word of a detection
+func (c censorer) censor(word string, d detection) string {
+	if d.Replacement != "" {
+		return c.escape(d.Replacement, d)
+	}
+
+	style := c.styleOf(d)
+	// Code and URLs cannot be struck through
+	if d.Verbatim && style == censorStyleStrikethrough {
+		style = censorStyleMask
+	}
+
+	var censored string
+	length := runeLength(word)
+	switch style {
+	case censorStyleFirstLetter:
+		censored = keepLetters(word, 1, 0, c.character)
+	case censorStyleFirstLastLetter:
+		censored = keepLetters(word, 1, 1, c.character)
+	case censorStyleFixed:
+		censored = strings.Repeat(c.character, censorFixedLength)
+	case censorStyleGrawlix:
+		censored = grawlix(length)
+	case censorStyleStrikethrough:
+		return "~~" + word + "~~"
+	case censorStylePlaceholder:
+		censored = c.placeholder
+	default:
+		censored = strings.Repeat(c.character, length)
+	}
+
+	return c.escape(censored, d)
+}
+
+// escape escapes the markdown characters of censored text when enabled
+func (c censorer) escape(censored string, d detection) string {
+	if !c.escapeMarkdown {
+		return censored
+	}
+	// Markdown is not interpreted in code and URLs, where escapes would show
+	if d.Verbatim {
+		return unescapeMarkdown(censored)
+	}
+	return escapeMarkdown(censored)
+}
+
+// keepLetters masks a word except for its first and last letters. Words too short to keep the
+// letters are masked entirely.
+func keepLetters(word string, first, last int, character string) string {
+	letters := []rune(word)
+	if len(letters) <= first+last+1 {
+		return strings.Repeat(character, len(letters))
+	}
+	return string(letters[:first]) + strings.Repeat(character, len(letters)-first-last) + string(letters[len(letters)-last:])
+}
+
+// grawlix returns a string of random symbols, never repeating the same symbol twice in a row
+func grawlix(length int) string {
+	var builder strings.Builder
+	previous := -1
+	for i := 0; i < length; i++ {
+		symbol := rand.IntN(len(grawlixSymbols))
+		if symbol == previous {
+			symbol = (symbol + 1) % len(grawlixSymbols)
+		}
+		builder.WriteByte(grawlixSymbols[symbol])
+		previous = symbol
+	}
+	return builder.String()
+}
+
+// escapeMarkdown escapes the markdown special characters of a text with backslashes. Characters
+// that are already escaped, e.g. in the censor character "\*", are kept as they are.
+func escapeMarkdown(text string) string {
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '\\' && i+1 < len(text) && strings.IndexByte(markdownSpecialCharacters, text[i+1]) >= 0 {
+			builder.WriteByte(c)
+			builder.WriteByte(text[i+1])
+			i++
+			continue
+		}
+		if strings.IndexByte(markdownSpecialCharacters, c) >= 0 {
+			builder.WriteByte('\\')
+		}
+		builder.WriteByte(c)
+	}
+	return builder.String()
+}
+
+// unescapeMarkdown removes the backslashes escaping markdown special characters
+func unescapeMarkdown(text string) string {
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) && strings.IndexByte(markdownSpecialCharacters, text[i+1]) >= 0 {
+			i++
+		}
+		builder.WriteByte(text[i])
+	}
+	return builder.String()
+}
+
+// applyTermOptions applies the options of the terms to their detections, under the installation
+// policy
+func applyTermOptions(detections []detection, terms *termSet) []detection {
+	return applyTermPolicy(detections, terms, nil)
+}
+
+// applyTermPolicy looks up the term of the term set each detection matched, records its category and replaces
+// the detection with the euphemism of the term, if any, in the capitalization of the detected word.
+// The detections of terms the policy does not filter and of words it allows are dropped.
+func applyTermPolicy(detections []detection, terms *termSet, policy *termPolicy) []detection {
+	index := terms.termIndex
+	filtered := detections[:0]
+	for _, d := range detections {
+		if policy.allows(d.Word) {
+			continue
+		}
+		word := d.Term
+		if word == "" {
+			word = d.Word
+		}
+		if t, ok := index.lookup(word); ok {
+			if !policy.filters(t) {
+				continue
+			}
+			d.Category = t.category()
+			if replacement := t.replacement(); replacement != "" {
+				d.Replacement = matchCapitalization(replacement, d.Word)
+			}
+		}
+		filtered = append(filtered, d)
+	}
+	return filtered
+}
+
+// matchCapitalization writes a replacement in the capitalization of the word it replaces: upper
+// case ("FUCK" to "FUDGE"), capitalized ("Fuck" to "Fudge"), lower case, or letter by letter for
+// mixed case words
+func matchCapitalization(replacement, word string) string {
+	var upper, lower int
+	var letters []rune
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			letters = append(letters, r)
+			if unicode.IsUpper(r) {
+				upper++
+			} else if unicode.IsLower(r) {
+				lower++
+			}
+		}
+	}
+
+	switch {
+	case upper == 0:
+		return replacement
+	case lower == 0 && upper > 1:
+		return strings.ToUpper(replacement)
+	case unicode.IsUpper(letters[0]) && upper == 1:
+		first := []rune(replacement)
+		for i, r := range first {
+			if unicode.IsLetter(r) {
+				first[i] = unicode.ToUpper(r)
+				break
+			}
+		}
+		return string(first)
+	}
+
+	// Mixed case: follow the case of the letter at the same position, and of the last letter beyond
+	var builder strings.Builder
+	position := 0
+	for _, r := range replacement {
+		if !unicode.IsLetter(r) {
+			builder.WriteRune(r)
+			continue
+		}
+		if unicode.IsUpper(letters[min(position, len(letters)-1)]) {
+			builder.WriteRune(unicode.ToUpper(r))
+		} else {
+			builder.WriteRune(unicode.ToLower(r))
+		}
+		position++
+	}
+	return builder.String()
+}
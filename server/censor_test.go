@@ -0,0 +1,131 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestCensorStyles(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         configuration
+		input          string
+		expectedOutput string
+	}{
+		{"full mask by default", configuration{CensorCharacter: "*", BadWordsList: "fuck"}, "fuck you", "**** you"},
+		{"first letter", configuration{CensorCharacter: "*", CensorStyle: "first-letter", BadWordsList: "fuck"}, "fuck you", "f*** you"},
+		{"first and last letters", configuration{CensorCharacter: "*", CensorStyle: "first-last-letter", BadWordsList: "fuck"}, "Fuck you", "F**k you"},
+		{"short words are fully masked", configuration{CensorCharacter: "*", CensorStyle: "first-last-letter", BadWordsList: "ass"}, "ass", "***"},
+		{"fixed length", configuration{CensorCharacter: "*", CensorStyle: "fixed", BadWordsList: "motherfucker"}, "motherfucker!", "****!"},
+		{"strikethrough", configuration{CensorCharacter: "*", CensorStyle: "strikethrough", BadWordsList: "shit"}, "oh shit", "oh ~~shit~~"},
+		{"placeholder", configuration{CensorCharacter: "*", CensorStyle: "placeholder", CensorPlaceholder: "[redacted]", BadWordsList: "shit"}, "oh shit", "oh [redacted]"},
+		{"default placeholder", configuration{CensorCharacter: "*", CensorStyle: "placeholder", BadWordsList: "shit"}, "oh shit", "oh [censored]"},
+		{"category style", configuration{CensorCharacter: "*", CensorCategoryStyles: "slurs=placeholder", BadWordsList: "fuck,slur#category=slurs"}, "fuck slur", "**** [censored]"},
+		{"category style of a misspelling", configuration{CensorCharacter: "*", CensorCategoryStyles: "mild=first-letter", FuzzyMatching: true, FuzzyMaxDistance: 1, BadWordsList: "bitch#category=mild"}, "biitch", "b*****"},
+		{"category style of a pattern", configuration{CensorCharacter: "*", CensorCategoryStyles: "mild=fixed", BadWordsList: "sh[i1]t#category=mild"}, "sh1tty sh1t", "sh1tty ****"},
+		{"category without a style", configuration{CensorCharacter: "*", CensorStyle: "first-letter", BadWordsList: "fuck#category=other"}, "fuck", "f***"},
+		{"escaped mask", configuration{CensorCharacter: "*", CensorEscapeMarkdown: true, BadWordsList: "fuck"}, "fuck", `\*\*\*\*`},
+		{"escaped censor character is kept", configuration{CensorCharacter: `\*`, CensorEscapeMarkdown: true, BadWordsList: "fuck"}, "fuck", `\*\*\*\*`},
+		{"escaped placeholder", configuration{CensorCharacter: "*", CensorStyle: "placeholder", CensorEscapeMarkdown: true, BadWordsList: "shit"}, "shit", `\[censored\]`},
+		{"code is not escaped", configuration{CensorCharacter: `\*`, CensorEscapeMarkdown: true, BadWordsList: "shit"}, "`shit` shit", "`****` \\*\\*\\*\\*"},
+		{"code is not struck through", configuration{CensorCharacter: "*", CensorStyle: "strikethrough", BadWordsList: "shit"}, "`shit` shit", "`****` ~~shit~~"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.config
+			p := newTestPlugin(t, &config)
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+
+	t.Run("grawlix", func(t *testing.T) {
+		p := newTestPlugin(t, &configuration{CensorStyle: "grawlix", BadWordsList: "fuck"})
+
+		rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: "fuck you"})
+		assert.Empty(t, s)
+		assert.Regexp(t, regexp.MustCompile(`^[@#$%&!]{4} you$`), rpost.Message)
+	})
+
+	t.Run("invalid styles are rejected", func(t *testing.T) {
+		p := &Plugin{configuration: &configuration{CensorStyle: "blur"}}
+		assert.Error(t, p.compileWordRegexes("fuck"))
+
+		p = &Plugin{configuration: &configuration{CensorCategoryStyles: "slurs=blur"}}
+		assert.Error(t, p.compileWordRegexes("fuck"))
+
+		p = &Plugin{configuration: &configuration{CensorCategoryStyles: "placeholder"}}
+		assert.Error(t, p.compileWordRegexes("fuck"))
+	})
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	assert.Equal(t, `\*\*`, escapeMarkdown("**"))
+	assert.Equal(t, `\*\*`, escapeMarkdown(`\*\*`))
+	assert.Equal(t, `@\#$%&!`, escapeMarkdown("@#$%&!"))
+	assert.Equal(t, "**", unescapeMarkdown(`\*\*`))
+}
+
+func TestTermReplacements(t *testing.T) {
+	testCases := []struct {
+		name           string
+		wordList       string
+		style          string
+		input          string
+		expectedOutput string
+	}{
+		{"lower case", "fuck#replace=fudge", "", "fuck it", "fudge it"},
+		{"upper case", "fuck#replace=fudge", "", "FUCK IT", "FUDGE IT"},
+		{"capitalized", "fuck#replace=fudge", "", "Fuck it", "Fudge it"},
+		{"mixed case", "fuck#replace=fudge", "", "fUcK it", "fUdGE it"},
+		{"phrase", "mother fucker#replace=mother trucker", "", "Mother Fucker", "Mother Trucker"},
+		{"pattern", "sh[i1]t#replace=shoot", "", "SH1T", "SHOOT"},
+		{"variant of the term", "bitch#replace=witch", "", "Biitch", "Witch"},
+		{"replacement keeps its case", "damn#replace=Darn", "", "damn it", "Darn it"},
+		{"terms without a replacement use the censor style", "fuck#replace=fudge,shit", "first-letter", "fuck shit", "fudge s***"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &Plugin{configuration: &configuration{
+				CensorCharacter:  "*",
+				CensorStyle:      tc.style,
+				BadWordsList:     tc.wordList,
+				FuzzyMatching:    true,
+				FuzzyMaxDistance: 1,
+			}}
+			require.NoError(t, p.compileWordRegexes(tc.wordList))
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+
+	t.Run("replacements are escaped", func(t *testing.T) {
+		p := newTestPlugin(t, &configuration{CensorCharacter: "*", CensorEscapeMarkdown: true, BadWordsList: "fuck#replace=f_ck_"})
+
+		rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: "fuck it"})
+		assert.Empty(t, s)
+		assert.Equal(t, `f\_ck\_ it`, rpost.Message)
+	})
+}
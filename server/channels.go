@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+// channelActionRevert restores the previous display name, header or purpose of a channel, which
+// are otherwise censored
+const channelActionRevert = "revert"
+
+// channelField is a user-visible text of a channel
+type channelField struct {
+	Path     string
+	Name     string
+	Markdown bool
+	// OldProp is the prop of the system message of an update that holds the previous text
+	OldProp string
+	get     func(channel *model.Channel) *string
+}
+
+// channelFields are the texts of a channel that are checked
+var channelFields = []channelField{
+	{"display_name", "display name", false, "old_displayname", func(c *model.Channel) *string { return &c.DisplayName }},
+	{"header", "header", true, "old_header", func(c *model.Channel) *string { return &c.Header }},
+	{"purpose", "purpose", false, "old_purpose", func(c *model.Channel) *string { return &c.Purpose }},
+}
+
+// ChannelHasBeenCreated checks the display name, header and purpose of new channels
+func (p *Plugin) ChannelHasBeenCreated(_ *plugin.Context, channel *model.Channel) {
+	p.enforceChannelPolicy(channel, channel.CreatorId, nil)
+}
+
+// MessageHasBeenPosted checks the display name, header and purpose of channels when the system
+// message of their update is posted
+func (p *Plugin) MessageHasBeenPosted(_ *plugin.Context, post *model.Post) {
+	switch post.Type {
+	case model.PostTypeDisplaynameChange, model.PostTypeHeaderChange, model.PostTypePurposeChange:
+	default:
+		return
+	}
+	if !p.getConfiguration().FilterChannels {
+		return
+	}
+
+	channel, appErr := p.API.GetChannel(post.ChannelId)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get an updated channel", "channel_id", post.ChannelId, "err", appErr.Error())
+		return
+	}
+	p.enforceChannelPolicy(channel, post.UserId, post.GetProps())
+}
+
+// enforceChannelPolicy censors or reverts the display name, header and purpose of a channel that
+// contain bad words, and notifies the user who set them and the moderators. Reverting restores the
+// previous text found in the props of the system message of an update, and otherwise clears the
+// header and purpose and censors the display name, which cannot be empty.
+func (p *Plugin) enforceChannelPolicy(channel *model.Channel, userID string, previous model.StringInterface) {
+	configuration := p.getConfiguration()
+	if !configuration.FilterChannels || channel == nil {
+		return
+	}
+
+	censor := p.newCensorer(configuration)
+	var changes, words []string
+	for _, field := range channelFields {
+		value := field.get(channel)
+		detections := p.detectText(channel.Id, visibleText{Path: field.Path, Text: *value, Markdown: field.Markdown}, configuration)
+		if len(detections) == 0 {
+			continue
+		}
+		words = append(words, detectedWords(detections)...)
+		p.logChannelDetections(channel, field.Path, detections)
+
+		if configuration.ChannelAction == channelActionRevert {
+			old, ok := previous[field.OldProp].(string)
+			if ok && len(p.detectText(channel.Id, visibleText{Path: field.Path, Text: old, Markdown: field.Markdown}, configuration)) == 0 {
+				*value = old
+				changes = append(changes, fmt.Sprintf("the %s was reverted", field.Name))
+				continue
+			}
+			if field.Path != "display_name" {
+				*value = ""
+				changes = append(changes, fmt.Sprintf("the %s was cleared", field.Name))
+				continue
+			}
+		}
+
+		*value = censorDetections(*value, detections, censor)
+		changes = append(changes, fmt.Sprintf("the %s was censored", field.Name))
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	if _, appErr := p.API.UpdateChannel(channel); appErr != nil {
+		p.API.LogWarn("Failed to update a channel containing profanity", "channel_id", channel.Id, "err", appErr.Error())
+		return
+	}
+
+	p.notifyUser(userID, fmt.Sprintf("The channel ~%s contained profanity (%s): %s.",
+		channel.Name, strings.Join(words, ", "), strings.Join(changes, ", ")))
+
+	if configuration.ModeratorUsernames == "" {
+		return
+	}
+	username := userID
+	if user, appErr := p.API.GetUser(userID); appErr == nil {
+		username = "@" + user.Username
+	}
+	p.notifyModerators(fmt.Sprintf("%s set profanity (%s) on the channel ~%s (%s): %s.",
+		username, strings.Join(words, ", "), channel.Name, channel.Id, strings.Join(changes, ", ")))
+}
+
+// logChannelDetections reports each detection of a text of a channel together with the strategy
+// that matched it
+func (p *Plugin) logChannelDetections(channel *model.Channel, path string, detections []detection) {
+	for _, d := range detections {
+		p.API.LogDebug("Profanity detected in channel", "channel_id", channel.Id, "path", path, "word", d.Word, "strategy", d.Strategy)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+)
+
+func TestChannelPolicy(t *testing.T) {
+	newPlugin := func(t *testing.T, config *configuration) (*Plugin, *plugintest.API) {
+		config.FilterChannels = true
+		config.CensorCharacter = "*"
+		config.BadWordsList = "fuck,shit"
+		p, api := newTestPluginWithAPI(t, config)
+		p.botID = "bot-id"
+		return p, api
+	}
+
+	// expectNotification expects a direct message from the bot to a user
+	expectNotification := func(api *plugintest.API, userID string, message string) {
+		api.On("GetDirectChannel", "bot-id", userID).Return(&model.Channel{Id: "dm-" + userID}, nil).Once()
+		api.On("CreatePost", &model.Post{UserId: "bot-id", ChannelId: "dm-" + userID, Message: message}).Return(&model.Post{}, nil).Once()
+	}
+
+	t.Run("new channel is censored", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{})
+		channel := &model.Channel{Id: "channel-id", Name: "fun", DisplayName: "Fuck Mondays", Header: "**shit** happens", Purpose: "Fun", CreatorId: "user-id"}
+
+		api.On("UpdateChannel", mock.Anything).Return(channel, nil).Once()
+		expectNotification(api, "user-id", "The channel ~fun contained profanity (Fuck, shit): the display name was censored, the header was censored.")
+
+		p.ChannelHasBeenCreated(&plugin.Context{}, channel)
+
+		assert.Equal(t, "**** Mondays", channel.DisplayName)
+		assert.Equal(t, "******** happens", channel.Header)
+		assert.Equal(t, "Fun", channel.Purpose)
+		api.AssertExpectations(t)
+	})
+
+	t.Run("new channel is reverted", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{ChannelAction: "revert"})
+		channel := &model.Channel{Id: "channel-id", Name: "fun", DisplayName: "Fuck Mondays", Purpose: "shit talk", CreatorId: "user-id"}
+
+		api.On("UpdateChannel", mock.Anything).Return(channel, nil).Once()
+		expectNotification(api, "user-id", "The channel ~fun contained profanity (Fuck, shit): the display name was censored, the purpose was cleared.")
+
+		p.ChannelHasBeenCreated(&plugin.Context{}, channel)
+
+		assert.Equal(t, "**** Mondays", channel.DisplayName)
+		assert.Equal(t, "", channel.Purpose)
+		api.AssertExpectations(t)
+	})
+
+	t.Run("updated header is reverted and moderators are informed", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{ChannelAction: "revert", ModeratorUsernames: "mod"})
+		channel := &model.Channel{Id: "channel-id", Name: "fun", DisplayName: "Fun", Header: "fuck this"}
+		post := &model.Post{Type: model.PostTypeHeaderChange, ChannelId: "channel-id", UserId: "user-id"}
+		post.AddProp("old_header", "Welcome")
+		post.AddProp("new_header", "fuck this")
+
+		api.On("GetChannel", "channel-id").Return(channel, nil)
+		api.On("UpdateChannel", mock.Anything).Return(channel, nil).Once()
+		api.On("GetUser", "user-id").Return(&model.User{Id: "user-id", Username: "alice"}, nil)
+		api.On("GetUserByUsername", "mod").Return(&model.User{Id: "mod-id", Username: "mod"}, nil)
+		expectNotification(api, "user-id", "The channel ~fun contained profanity (fuck): the header was reverted.")
+		expectNotification(api, "mod-id", "@alice set profanity (fuck) on the channel ~fun (channel-id): the header was reverted.")
+
+		p.MessageHasBeenPosted(&plugin.Context{}, post)
+
+		assert.Equal(t, "Welcome", channel.Header)
+		api.AssertExpectations(t)
+	})
+
+	t.Run("clean channel is unchanged", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{})
+		channel := &model.Channel{Id: "channel-id", Name: "fun", DisplayName: "Fun", Header: "Welcome"}
+
+		p.ChannelHasBeenCreated(&plugin.Context{}, channel)
+
+		api.AssertNotCalled(t, "UpdateChannel", mock.Anything)
+	})
+
+	t.Run("other posts are ignored", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{})
+
+		p.MessageHasBeenPosted(&plugin.Context{}, &model.Post{ChannelId: "channel-id", Message: "fuck"})
+
+		api.AssertNotCalled(t, "GetChannel", mock.Anything)
+	})
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// commonWordsList is the built-in list of common English words that are never matched by a
+// stem, misspelling, sound or encoding of a bad word, e.g. "hello" for "hell" or "duck" for "fuck"
+//
+//go:embed data/common_words.txt
+var commonWordsList string
+
+// commonWords is the set of the built-in common words
+var commonWords = func() map[string]bool {
+	words := map[string]bool{}
+	for _, word := range strings.Fields(commonWordsList) {
+		words[word] = true
+	}
+	return words
+}()
+
+// isCommonWord checks if a word, or its singular form, is a common English word
+func isCommonWord(word string) bool {
+	word = strings.ToLower(word)
+	if commonWords[word] {
+		return true
+	}
+	for _, suffix := range []string{"s", "es"} {
+		if singular, ok := strings.CutSuffix(word, suffix); ok && commonWords[singular] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	_ "embed"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// compoundWordsList is the built-in list of common words concatenated words are split into
+//
+//go:embed data/compound_words.txt
+var compoundWordsList string
+
+const (
+	// compoundMinSubWordLength is the length of the shortest sub-word a concatenated word is split into
+	compoundMinSubWordLength = 2
+	// compoundMaxWordLength is the length of the longest concatenated word that is split, to bound
+	// the cost of splitting
+	compoundMaxWordLength = 64
+)
+
+// compoundTokenRegex finds the candidate compounds of a text: runs of letters, digits and
+// underscores, which the \b word boundary treats as a single word
+var compoundTokenRegex = regexp.MustCompile(`[\p{L}\p{M}\p{N}_]+`)
+
+// compoundDictionary is the set of lowercased words concatenated words can be split into
+type compoundDictionary map[string]bool
+
+// compileCompoundDictionary builds the dictionary from the built-in list, the additional words
+// from the settings and the plain words among the terms
+func compileCompoundDictionary(terms []term, additionalWords string) compoundDictionary {
+	dictionary := compoundDictionary{}
+	for _, word := range strings.Fields(compoundWordsList) {
+		dictionary[word] = true
+	}
+	for _, word := range splitWordList(additionalWords) {
+		dictionary[strings.ToLower(word)] = true
+	}
+	for _, t := range terms {
+		if !isJapaneseWord(t.Word) && isPlainWord(t.Word) {
+			dictionary[strings.ToLower(t.Word)] = true
+		}
+	}
+	return dictionary
+}
+
+// contains checks if a lowercased word, or its singular form, is in the dictionary
+func (d compoundDictionary) contains(word string) bool {
+	if d[word] {
+		return true
+	}
+	singular := strings.TrimSuffix(word, "s")
+	return singular != word && d[singular]
+}
+
+// split splits a lowercased concatenated word into the fewest dictionary words, returning the
+// byte offsets at which it is split, or nil if the word is a dictionary word or cannot be split
+func (d compoundDictionary) split(word string) []int {
+	if d.contains(word) || utf8.RuneCountInString(word) > compoundMaxWordLength {
+		return nil
+	}
+
+	// fewest[i] is the fewest sub-words word[:i] splits into and previous[i] where the last one starts
+	fewest := make([]int, len(word)+1)
+	previous := make([]int, len(word)+1)
+	for i := 1; i <= len(word); i++ {
+		fewest[i] = -1
+	}
+
+	for end := 1; end <= len(word); end++ {
+		if end < len(word) && !utf8.RuneStart(word[end]) {
+			continue
+		}
+		for start := 0; start < end; start++ {
+			if fewest[start] < 0 || !utf8.RuneStart(word[start]) {
+				continue
+			}
+			subWord := word[start:end]
+			if utf8.RuneCountInString(subWord) < compoundMinSubWordLength || !d.contains(subWord) {
+				continue
+			}
+			if fewest[end] < 0 || fewest[start]+1 < fewest[end] {
+				fewest[end] = fewest[start] + 1
+				previous[end] = start
+			}
+		}
+	}
+
+	if fewest[len(word)] < 2 {
+		return nil
+	}
+
+	var offsets []int
+	for i := previous[len(word)]; i > 0; i = previous[i] {
+		offsets = append([]int{i}, offsets...)
+	}
+	return offsets
+}
+
+// compoundBoundaries returns the byte offsets at which a token is split into sub-words: around
+// underscores, between letters and digits, at camelCase humps and between the words of
+// concatenated words found in the dictionary
+func compoundBoundaries(token string, dictionary compoundDictionary) []int {
+	var boundaries []int
+
+	// Split into parts of the same kind first
+	var parts [][2]int
+	start := -1
+	var previous rune
+	for i, r := range token {
+		next, _ := utf8.DecodeRuneInString(token[i+utf8.RuneLen(r):])
+		switch {
+		case r == '_':
+			if start >= 0 {
+				parts = append(parts, [2]int{start, i})
+			}
+			start = -1
+		case start < 0:
+			start = i
+		case unicode.IsDigit(r) != unicode.IsDigit(previous),
+			unicode.IsUpper(r) && unicode.IsLower(previous),
+			unicode.IsUpper(r) && unicode.IsUpper(previous) && unicode.IsLower(next):
+			parts = append(parts, [2]int{start, i})
+			start = i
+		}
+		previous = r
+	}
+	if start >= 0 {
+		parts = append(parts, [2]int{start, len(token)})
+	}
+
+	for _, part := range parts {
+		// Underscores are word characters too, so every part is split from both of its sides
+		if part[0] > 0 {
+			boundaries = append(boundaries, part[0])
+		}
+
+		word := token[part[0]:part[1]]
+		// Lowercasing may change the byte length of a word, so only split words where it does not
+		if lowered := strings.ToLower(removeAccents(word)); isPlainWord(word) && len(lowered) == len(word) {
+			for _, offset := range dictionary.split(lowered) {
+				boundaries = append(boundaries, part[0]+offset)
+			}
+		}
+
+		if part[1] < len(token) {
+			boundaries = append(boundaries, part[1])
+		}
+	}
+
+	return slices.Compact(boundaries)
+}
+
+// splitCompounds separates the sub-words of every compound of the text with spaces and removes
+// accents, so that the bad words regex can match each sub-word on its own
+func splitCompounds(text string, dictionary compoundDictionary) normalizedText {
+	boundaries := map[int]bool{}
+	for _, match := range compoundTokenRegex.FindAllStringIndex(text, -1) {
+		for _, boundary := range compoundBoundaries(text[match[0]:match[1]], dictionary) {
+			boundaries[match[0]+boundary] = true
+		}
+	}
+
+	var builder normalizedTextBuilder
+	for i, r := range text {
+		if boundaries[i] {
+			builder.add(" ", i, i)
+		}
+		builder.add(removeAccents(string(r)), i, i+utf8.RuneLen(r))
+	}
+	return builder.build()
+}
+
+// detectCompoundWords matches the bad words against the sub-words of hashtags, camelCase,
+// snake_case and concatenated words, e.g. "fuck" in "#FuckMondays" or "shit" in "shit_show"
+func (p *Plugin) detectCompoundWords(text string, terms *termSet) []detection {
+	var detected []detection
+
+	dictionary := terms.compoundDictionary
+	regex := terms.asciiWordsRegex
+	if dictionary == nil || regex == nil {
+		return detected
+	}
+
+	split := splitCompounds(text, dictionary)
+	for _, match := range regex.FindAllStringIndex(split.text, -1) {
+		if match[1] > match[0] {
+			start, end := split.originalSpan(match[0], match[1])
+			d := newDetection(text, start, end)
+			d.Strategy = "compound"
+			d.Term = split.text[match[0]:match[1]]
+			detected = append(detected, d)
+		}
+	}
+
+	return detected
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestCompoundSplitting(t *testing.T) {
+	testCases := []struct {
+		name            string
+		wordList        string
+		enabled         bool
+		additionalWords string
+		input           string
+		expectedOutput  string
+	}{
+		{"compound splitting disabled by default", "fuck", false, "", "#FuckMondays", "#FuckMondays"},
+		{"hashtag", "fuck", true, "", "#FuckMondays", "#****Mondays"},
+		{"camelCase", "fuck", true, "", "fuckThis", "****This"},
+		{"camelCase phrase", "go to hell", true, "", "goToHell", "********"},
+		{"camelCase after acronym", "fuck", true, "", "WTFFuck", "WTF****"},
+		{"snake_case", "shit", true, "", "shit_show", "****_show"},
+		{"digits", "fuck", true, "", "fuck2day", "****2day"},
+		{"concatenated words", "fuck", true, "", "#fuckmondays", "#****mondays"},
+		{"concatenated words with several sub-words", "hell", true, "", "gotohell", "goto****"},
+		{"concatenated bad words", "dumb,ass", true, "", "dumbass", "*******"},
+		{"dictionary words are not split", "ass", true, "", "class assassin embarrass", "class assassin embarrass"},
+		{"unknown words are not split", "cunt", true, "", "Scunthorpe", "Scunthorpe"},
+		{"additional words", "fuck", true, "standup", "fuckstandup", "****standup"},
+		{"whole words still match", "fuck", true, "", "fuck you", "**** you"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPlugin(t, &configuration{
+				CensorCharacter:        "*",
+				BadWordsList:           tc.wordList,
+				CompoundSplitting:      tc.enabled,
+				CompoundSplittingWords: tc.additionalWords,
+			})
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+}
+
+func TestCompoundBoundaries(t *testing.T) {
+	dictionary := compileCompoundDictionary(parseTerms([]string{"fuck"}), "")
+
+	testCases := []struct {
+		token    string
+		expected []int
+	}{
+		{"fuck", nil},
+		{"FuckMondays", []int{4}},
+		{"shit_show", []int{4, 5}},
+		{"_fuck_", []int{1, 5}},
+		{"HTTPServer", []int{4}},
+		{"fuckthis", []int{4}},
+		{"assassin", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.token, func(t *testing.T) {
+			assert.Equal(t, tc.expected, compoundBoundaries(tc.token, dictionary))
+		})
+	}
+}
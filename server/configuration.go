@@ -27,6 +27,117 @@ type configuration struct {
 	CensorCharacter string
 	BadWordsList    string
 	WarningMessage  string `json:"WarningMessage"`
+
+	// CensorStyle selects how detected words are censored, e.g. "mask", "first-last-letter" or
+	// "placeholder"
+	CensorStyle string
+	// CensorPlaceholder replaces detected words in the placeholder style
+	CensorPlaceholder string
+	// CensorCategoryStyles selects the censor style of each category of terms, separated by
+	// commas, e.g. "slurs=placeholder, mild=first-letter"
+	CensorCategoryStyles string
+	// CensorEscapeMarkdown escapes the markdown characters of censored words
+	CensorEscapeMarkdown bool
+
+	// ExcludeMentions, ExcludeChannelLinks, ExcludeEmojiShortcodes and ExcludeHashtags never censor
+	// the mentions of existing users, links to existing channels, shortcodes of existing emoji and
+	// hashtags of known user or channel names
+	ExcludeMentions        bool
+	ExcludeChannelLinks    bool
+	ExcludeEmojiShortcodes bool
+	ExcludeHashtags        bool
+
+	// StemmingLanguages lists the languages whose inflected forms of plain words are matched,
+	// separated by commas, e.g. "english,spanish"
+	StemmingLanguages string
+
+	// FuzzyMatching enables matching misspellings of plain words by edit distance
+	FuzzyMatching bool
+	// FuzzyMaxDistance caps the edit distance of fuzzy matches, which is also scaled by word length
+	FuzzyMaxDistance int
+
+	// PhoneticMinLength is the shortest word that is matched phonetically
+	PhoneticMinLength int
+
+	// CompoundSplitting enables matching the sub-words of hashtags, camelCase, snake_case and
+	// concatenated words
+	CompoundSplitting bool
+	// CompoundSplittingWords lists additional words concatenated words are split into, separated by commas
+	CompoundSplittingWords string
+
+	// Decoders lists the encodings whose decoded views of the messages are also matched, separated
+	// by commas, e.g. "reversed,rot13,base64"
+	Decoders string
+
+	// MarkdownSkipCodeBlocks, MarkdownSkipInlineCode, MarkdownSkipLinkURLs, MarkdownSkipLinkText
+	// and MarkdownSkipQuotes leave the corresponding regions of markdown messages unscanned
+	MarkdownSkipCodeBlocks bool
+	MarkdownSkipInlineCode bool
+	MarkdownSkipLinkURLs   bool
+	MarkdownSkipLinkText   bool
+	MarkdownSkipQuotes     bool
+
+	// FilterFileUploads checks the names of uploaded files and the text of the supported formats
+	FilterFileUploads bool
+	// FileNameAction is "reject" to reject uploads with profane file names, or "rename" to censor them
+	FileNameAction string
+	// FileScanMaxSizeKB is the size in kilobytes up to which the text of uploaded files is scanned
+	FileScanMaxSizeKB int
+
+	// FilterChannels checks the display names, headers and purposes of channels
+	FilterChannels bool
+	// ChannelAction is "censor" to censor profane channel texts, or "revert" to restore their
+	// previous value
+	ChannelAction string
+
+	// FilterProfiles checks the usernames, nicknames, names, positions and custom statuses of users
+	FilterProfiles bool
+	// ProfileAction is "flag" to only report profile fields containing bad words, or "reset" to
+	// also clear them
+	ProfileAction string
+
+	// FilterReactions removes reactions whose emoji name contains bad words
+	FilterReactions bool
+
+	// SweepIntervalHours is the interval between sweeps over the profiles of existing users and
+	// the custom emoji, or 0 to disable the sweeps
+	SweepIntervalHours int
+
+	// ModeratorUsernames lists the users notified about censored content, separated by commas
+	ModeratorUsernames string
+
+	// EmojiReplacement replaces the emoji and shortcodes that match a term, e.g. ":see_no_evil:"
+	EmojiReplacement string
+
+	// JapaneseDictionary selects the Kagome system dictionary: "ipa" or "uni"
+	JapaneseDictionary string
+	// JapaneseTokenizerMode selects the Kagome tokenize mode: "normal", "search" or "extended"
+	JapaneseTokenizerMode string
+	// JapaneseMatchStrictness is the loosest strategy Japanese terms match with by default:
+	// "token-exact", "token-sequence" or "substring"
+	JapaneseMatchStrictness string
+	// JapaneseUserDictionary names a Kagome user dictionary stored in the KV store or file store
+	JapaneseUserDictionary string
+
+	// ScopedPolicies is a JSON list of the policies of teams, channels, channel types and author
+	// types layered over the installation policy, each adding, removing and allowing words and
+	// overriding the action and censor style
+	ScopedPolicies string
+
+	// ExemptUsers lists the user IDs and usernames whose posts are not filtered, separated by commas
+	ExemptUsers string
+	// ExemptGroups lists the names or IDs of the LDAP and custom groups whose members' posts are
+	// not filtered, separated by commas
+	ExemptGroups string
+	// ExemptRoles lists the team and channel roles whose posts are not filtered, separated by
+	// commas, e.g. "team_admin, channel_admin"
+	ExemptRoles string
+
+	// termPolicy selects the terms filtered in the scope of a post, resolved from its channel and
+	// author
+	termPolicy *termPolicy
+	// exempt leaves the posts of the scope unfiltered
+	exempt bool
 }
 
 // Clone shallow copies the configuration. Your implementation may require a deep copy if
@@ -89,55 +200,187 @@ func (p *Plugin) OnConfigurationChange() error {
 	// Normalize Japanese commas to ASCII commas in BadWordsList for consistent processing
 	configuration.BadWordsList = normalizeWordListCommas(configuration.BadWordsList)
 
+	// Build the Japanese tokenizer and compile the terms before the configuration is applied, so
+	// that an invalid configuration leaves the previous one in place
+	japaneseTokenizer, japaneseTokenizeMode, err := p.newJapaneseTokenizer(configuration)
+	if err != nil {
+		return err
+	}
+	if err := p.compileTerms(configuration, configuration.BadWordsList); err != nil {
+		return err
+	}
+
 	p.setConfiguration(configuration)
+	p.setJapaneseTokenizer(japaneseTokenizer, japaneseTokenizeMode)
+
+	// Forget the exemptions and scopes resolved under the previous configuration
+	p.resetExemptionCache()
+	p.resetScopeCache()
+
+	// Sweep the profiles of existing users and the custom emoji at the configured interval
+	if err := p.scheduleSweep(configuration); err != nil {
+		return errors.Wrap(err, "failed to schedule the sweep")
+	}
+
+	return nil
+}
+
+// compileWordRegexes compiles the terms of the word list with the active configuration
+func (p *Plugin) compileWordRegexes(wordList string) error {
+	return p.compileTerms(p.getConfiguration(), wordList)
+}
+
+// termSet is the compiled terms of a word list: the bad words list, or the words added by a policy
+type termSet struct {
+	// Word list the terms were compiled from
+	wordList string
+
+	// Pre-compiled regex patterns for performance
+	asciiWordsRegex    *regexp.Regexp
+	japaneseWordsRegex *regexp.Regexp
 
-	// Compile regex patterns for both ASCII and Japanese words
-	if err := p.compileWordRegexes(configuration.BadWordsList); err != nil {
+	// Pre-compiled canonical keys of the Hindi terms
+	hindiTerms []hindiTerm
+
+	// Pre-computed canonical keys of the emoji and shortcode terms
+	emojiTerms []emojiTerm
+
+	// Pre-computed stems of the terms for each stemming language
+	stemmedTerms stemmedTerms
+
+	// Pre-built edit distance index of the terms for fuzzy matching
+	fuzzyIndex *bkTree
+
+	// Pre-computed phonetic codes of the terms marked with the phonetic option
+	phoneticIndex *phoneticIndex
+
+	// Dictionary concatenated words are split into when compound splitting is enabled
+	compoundDictionary compoundDictionary
+
+	// Index of the terms, to look up the options of the term a word matched
+	termIndex *termIndex
+}
+
+// compileTerms compiles the terms of the word list and of the policies with the given
+// configuration, and swaps them in
+func (p *Plugin) compileTerms(configuration *configuration, wordList string) error {
+
+	// Compile the terms added by policies on their own, as they are only filtered within their scope
+	policies, err := parseScopedPolicies(configuration.ScopedPolicies)
+	if err != nil {
+		return err
+	}
+	if err := compilePolicyTerms(configuration, policies); err != nil {
+		return err
+	}
+
+	terms, err := compileTermSet(configuration, wordList)
+	if err != nil {
+		return err
+	}
+
+	// Select the encodings whose decoded views of the messages are matched
+	encodings, err := parseDecoders(configuration.Decoders)
+	if err != nil {
 		return err
 	}
 
-	// Initialize Japanese tokenizer
-	if err := p.initializeJapaneseTokenizer(); err != nil {
+	// Validate the censor styles
+	if _, err := parseCensorStyle(configuration.CensorStyle); err != nil {
 		return err
 	}
+	categoryStyles, err := parseCategoryStyles(configuration.CensorCategoryStyles)
+	if err != nil {
+		return err
+	}
+
+	// Swap in the compiled terms at once, so that hooks running concurrently never see a mix of
+	// the previous and the new configuration
+	p.configurationLock.Lock()
+	defer p.configurationLock.Unlock()
+
+	p.scopedPolicies = policies
+	p.terms = terms
+	p.decoders = encodings
+	p.censorCategoryStyles = categoryStyles
 
 	return nil
 }
 
-// compileWordRegexes compiles regex patterns for both ASCII and Japanese words
-func (p *Plugin) compileWordRegexes(wordList string) error {
-	words := splitWordList(wordList)
-	asciiWords, japaneseWords := separateASCIIAndJapanese(words)
+// compileTermSet compiles regex patterns for both ASCII and Japanese words, and the canonical keys of
+// the Hindi and emoji words, with the given configuration
+func compileTermSet(configuration *configuration, wordList string) (*termSet, error) {
+	hindiTerms, otherTerms := separateHindiTerms(parseTerms(splitWordList(wordList)))
+	emojiTerms, otherTerms := separateEmojiTerms(otherTerms)
+	asciiWords, japaneseWords := separateASCIIAndJapanese(termWords(otherTerms))
 
 	// Compile ASCII words regex
+	var asciiRegex *regexp.Regexp
 	if len(asciiWords) > 0 {
 		// Sort by length (longest first) to match longer words first
 		sort.Slice(asciiWords, func(i, j int) bool { return len(asciiWords[i]) > len(asciiWords[j]) })
 		asciiRegexStr := fmt.Sprintf(`(?mi)\b(%s)\b`, strings.Join(asciiWords, "|"))
-		asciiRegex, err := regexp.Compile(asciiRegexStr)
+		var err error
+		asciiRegex, err = regexp.Compile(asciiRegexStr)
 		if err != nil {
-			return fmt.Errorf("failed to compile ASCII words regex: %w", err)
+			return nil, fmt.Errorf("failed to compile ASCII words regex: %w", err)
 		}
-		p.asciiWordsRegex = asciiRegex
-	} else {
-		p.asciiWordsRegex = nil
 	}
 
-	// Compile Japanese words regex
+	// Compile Japanese words regex. Words are normalized the same way as the scanned text, so
+	// that e.g. katakana, hiragana and half-width spellings match each other.
+	var japaneseRegex *regexp.Regexp
 	if len(japaneseWords) > 0 {
 		var escapedWords []string
 		for _, word := range japaneseWords {
-			escapedWords = append(escapedWords, regexp.QuoteMeta(strings.ToLower(strings.TrimSpace(word))))
+			if normalized := normalizeJapanese(strings.TrimSpace(word)).text; normalized != "" {
+				escapedWords = append(escapedWords, regexp.QuoteMeta(normalized))
+			}
 		}
-		japaneseRegexStr := fmt.Sprintf(`(?i)\b(%s)\b`, strings.Join(escapedWords, "|"))
-		japaneseRegex, err := regexp.Compile(japaneseRegexStr)
+		// Sort by length (longest first) to match longer words first
+		sort.Slice(escapedWords, func(i, j int) bool { return len(escapedWords[i]) > len(escapedWords[j]) })
+		japaneseRegexStr := fmt.Sprintf(`(%s)`, strings.Join(escapedWords, "|"))
+		var err error
+		japaneseRegex, err = regexp.Compile(japaneseRegexStr)
 		if err != nil {
-			return fmt.Errorf("failed to compile Japanese words regex: %w", err)
+			return nil, fmt.Errorf("failed to compile Japanese words regex: %w", err)
 		}
-		p.japaneseWordsRegex = japaneseRegex
-	} else {
-		p.japaneseWordsRegex = nil
 	}
 
-	return nil
+	// Compute the stems of the plain words for each stemming language
+	languages, err := parseStemmingLanguages(configuration.StemmingLanguages)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the edit distance index of the plain words for fuzzy matching
+	var fuzzyIndex *bkTree
+	if configuration.FuzzyMatching && configuration.FuzzyMaxDistance > 0 {
+		fuzzyIndex = newBKTree(otherTerms, configuration.FuzzyMaxDistance)
+	}
+
+	// Build the dictionary hashtags and concatenated words are split into
+	var compounds compoundDictionary
+	if configuration.CompoundSplitting {
+		compounds = compileCompoundDictionary(otherTerms, configuration.CompoundSplittingWords)
+	}
+
+	// Compute the phonetic codes of the terms marked with the phonetic option
+	phoneticIndex := compilePhoneticIndex(otherTerms, configuration.PhoneticMinLength)
+
+	// Index the terms to look up their categories
+	termIndex := compileTermIndex(parseTerms(splitWordList(wordList)))
+
+	return &termSet{
+		wordList:           wordList,
+		asciiWordsRegex:    asciiRegex,
+		japaneseWordsRegex: japaneseRegex,
+		hindiTerms:         compileHindiTerms(hindiTerms),
+		emojiTerms:         compileEmojiTerms(emojiTerms),
+		stemmedTerms:       compileStemmedTerms(otherTerms, languages),
+		fuzzyIndex:         fuzzyIndex,
+		phoneticIndex:      phoneticIndex,
+		compoundDictionary: compounds,
+		termIndex:          termIndex,
+	}, nil
 }
@@ -17,7 +17,7 @@ func TestCompileWordRegexes(t *testing.T) {
 		err := p.compileWordRegexes(p.getConfiguration().BadWordsList)
 		assert.NoError(t, err)
 
-		asciiRegex := p.getASCIIWordsRegex()
+		asciiRegex := p.getTerms().asciiWordsRegex
 		assert.NotNil(t, asciiRegex)
 		assert.Equal(t, `(?mi)\b(def ghi|abc)\b`, asciiRegex.String())
 	})
@@ -32,7 +32,7 @@ func TestCompileWordRegexes(t *testing.T) {
 		err := p2.compileWordRegexes(p2.getConfiguration().BadWordsList)
 		assert.NoError(t, err)
 
-		asciiRegex := p2.getASCIIWordsRegex()
+		asciiRegex := p2.getTerms().asciiWordsRegex
 		assert.NotNil(t, asciiRegex)
 		assert.Equal(t, `(?mi)\b(abc def|abc)\b`, asciiRegex.String())
 	})
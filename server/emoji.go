@@ -0,0 +1,255 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	zeroWidthJoiner     = '\u200D'
+	combiningKeycap     = '\u20E3'
+	emojiVariation      = '\uFE0F'
+	textVariation       = '\uFE0E'
+	skinToneModifierMin = '\U0001F3FB'
+	skinToneModifierMax = '\U0001F3FF'
+	regionalIndicatorA  = '\U0001F1E6'
+	regionalIndicatorZ  = '\U0001F1FF'
+	tagMin              = '\U000E0020'
+	tagMax              = '\U000E007F'
+)
+
+// emojiShortcodeRegex finds Mattermost emoji shortcodes such as ":middle_finger:"
+var emojiShortcodeRegex = regexp.MustCompile(`:[a-zA-Z0-9_+\-]+:`)
+
+// emojiTerm is a bad emoji, or sequence of emoji, reduced to the canonical key of each emoji
+type emojiTerm struct {
+	Word string
+	Keys []string
+}
+
+// emojiToken is an emoji or shortcode of the scanned text together with its byte span in the
+// original text
+type emojiToken struct {
+	Key        string
+	Start, End int
+}
+
+// isEmojiRune checks if a rune starts an emoji, as opposed to the modifiers and joiners that can
+// follow it
+func isEmojiRune(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return !isSkinToneModifier(r)
+	case r >= 0x2300 && r <= 0x23FF, r >= 0x2600 && r <= 0x27BF, r >= 0x2B00 && r <= 0x2BFF:
+		return unicode.Is(unicode.So, r)
+	}
+	return false
+}
+
+// isSkinToneModifier checks if a rune is one of the five Fitzpatrick skin tone modifiers
+func isSkinToneModifier(r rune) bool {
+	return r >= skinToneModifierMin && r <= skinToneModifierMax
+}
+
+// isRegionalIndicator checks if a rune is one of the letters flags are made of
+func isRegionalIndicator(r rune) bool {
+	return r >= regionalIndicatorA && r <= regionalIndicatorZ
+}
+
+// isEmojiWord checks if a word is an emoji shortcode or contains Unicode emoji
+func isEmojiWord(word string) bool {
+	if strings.HasPrefix(word, ":") && strings.HasSuffix(word, ":") && emojiShortcodeRegex.MatchString(word) {
+		return true
+	}
+	for _, r := range word {
+		if isEmojiRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// separateEmojiTerms splits the emoji and shortcode terms from the remaining terms
+func separateEmojiTerms(terms []term) (emojiTerms, otherTerms []term) {
+	for _, t := range terms {
+		if isEmojiWord(t.Word) {
+			emojiTerms = append(emojiTerms, t)
+		} else {
+			otherTerms = append(otherTerms, t)
+		}
+	}
+	return emojiTerms, otherTerms
+}
+
+// emojiKey returns the canonical key of an emoji: its code points without skin tones and
+// variation selectors, so that every skin tone and presentation of an emoji shares the same key
+func emojiKey(emoji string) string {
+	var codePoints []string
+	for _, r := range emoji {
+		if isSkinToneModifier(r) || r == emojiVariation || r == textVariation {
+			continue
+		}
+		codePoints = append(codePoints, strconv.FormatInt(int64(r), 16))
+	}
+	return strings.Join(codePoints, "-")
+}
+
+// shortcodeKey returns the canonical key of a shortcode: the key of the system emoji it names, or
+// the shortcode itself for custom emoji
+func shortcodeKey(shortcode string) string {
+	name := strings.ToLower(strings.Trim(shortcode, ":"))
+	codePoints, ok := model.SystemEmojis[name]
+	if !ok {
+		return ":" + name + ":"
+	}
+
+	var emoji strings.Builder
+	for _, codePoint := range strings.Split(codePoints, "-") {
+		r, err := strconv.ParseInt(codePoint, 16, 32)
+		if err != nil {
+			return ":" + name + ":"
+		}
+		emoji.WriteRune(rune(r))
+	}
+	return emojiKey(emoji.String())
+}
+
+// emojiClusterEnd returns the end of the emoji starting at start: the emoji together with its
+// modifiers, variation selectors, keycap and tags, and the emoji joined to it by zero width joiners
+func emojiClusterEnd(text string, start int) int {
+	r, size := utf8.DecodeRuneInString(text[start:])
+	end := start + size
+
+	// A flag is a pair of regional indicators
+	if isRegionalIndicator(r) {
+		if next, nextSize := utf8.DecodeRuneInString(text[end:]); isRegionalIndicator(next) {
+			end += nextSize
+		}
+		return end
+	}
+
+	for end < len(text) {
+		next, nextSize := utf8.DecodeRuneInString(text[end:])
+		switch {
+		case isSkinToneModifier(next), next == emojiVariation, next == textVariation, next == combiningKeycap,
+			next >= tagMin && next <= tagMax:
+			end += nextSize
+		case next == zeroWidthJoiner:
+			joined, joinedSize := utf8.DecodeRuneInString(text[end+nextSize:])
+			if !isEmojiRune(joined) {
+				return end
+			}
+			end += nextSize + joinedSize
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+// tokenizeEmoji finds the emoji and shortcodes of a text
+func tokenizeEmoji(text string) []emojiToken {
+	var tokens []emojiToken
+
+	shortcodes := emojiShortcodeRegex.FindAllStringIndex(text, -1)
+	for i := 0; i < len(text); {
+		if len(shortcodes) > 0 && shortcodes[0][0] == i {
+			tokens = append(tokens, emojiToken{Key: shortcodeKey(text[i:shortcodes[0][1]]), Start: i, End: shortcodes[0][1]})
+			i = shortcodes[0][1]
+			shortcodes = shortcodes[1:]
+			continue
+		}
+		if len(shortcodes) > 0 && shortcodes[0][0] < i {
+			shortcodes = shortcodes[1:]
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isEmojiRune(r) {
+			i += size
+			continue
+		}
+		end := emojiClusterEnd(text, i)
+		tokens = append(tokens, emojiToken{Key: emojiKey(text[i:end]), Start: i, End: end})
+		i = end
+	}
+
+	return tokens
+}
+
+// compileEmojiTerms reduces each emoji term to the canonical keys of its emoji
+func compileEmojiTerms(terms []term) []emojiTerm {
+	var compiled []emojiTerm
+	for _, t := range terms {
+		var keys []string
+		for _, token := range tokenizeEmoji(t.Word) {
+			keys = append(keys, token.Key)
+		}
+		if len(keys) > 0 {
+			compiled = append(compiled, emojiTerm{Word: t.Word, Keys: keys})
+		}
+	}
+	return compiled
+}
+
+// emojiKeyMatches checks if an emoji of the text matches an emoji of a term, either exactly or as
+// one of the emoji joined into a zero width joiner sequence, e.g. "👩" in "👩‍💻"
+func emojiKeyMatches(textKey, termKey string) bool {
+	if textKey == termKey {
+		return true
+	}
+	if strings.HasPrefix(termKey, ":") || strings.Contains(termKey, "-") {
+		return false
+	}
+	for _, component := range strings.Split(textKey, "-") {
+		if component == termKey {
+			return true
+		}
+	}
+	return false
+}
+
+// detectEmoji matches the emoji terms against the emoji and shortcodes of the text. A sequence
+// term matches emoji that are only separated by whitespace.
+func (p *Plugin) detectEmoji(text string, terms *termSet) []detection {
+	var detected []detection
+
+	if len(terms.emojiTerms) == 0 {
+		return detected
+	}
+	replacement := p.getConfiguration().EmojiReplacement
+
+	tokens := tokenizeEmoji(text)
+	for i := range tokens {
+		for _, t := range terms.emojiTerms {
+			if i+len(t.Keys) > len(tokens) {
+				continue
+			}
+
+			matched := true
+			for j, key := range t.Keys {
+				token := tokens[i+j]
+				if !emojiKeyMatches(token.Key, key) ||
+					(j > 0 && strings.TrimSpace(text[tokens[i+j-1].End:token.Start]) != "") {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				d := newDetection(text, tokens[i].Start, tokens[i+len(t.Keys)-1].End)
+				d.Strategy = "emoji"
+				d.Replacement = replacement
+				d.Term = t.Word
+				detected = append(detected, d)
+				break
+			}
+		}
+	}
+
+	return detected
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestEmojiTerms(t *testing.T) {
+	testCases := []struct {
+		name           string
+		wordList       string
+		replacement    string
+		input          string
+		expectedOutput string
+	}{
+		{"unicode emoji", "🖕", ":see_no_evil:", "you 🖕", "you :see_no_evil:"},
+		{"skin tone", "🖕", ":see_no_evil:", "🖕🏿", ":see_no_evil:"},
+		{"shortcode of a unicode term", "🖕", ":see_no_evil:", ":middle_finger:", ":see_no_evil:"},
+		{"shortcode term", ":middle_finger:", ":see_no_evil:", "🖕 :fu:", ":see_no_evil: :see_no_evil:"},
+		{"skin tone shortcode", ":middle_finger:", ":see_no_evil:", ":middle_finger_dark_skin_tone:", ":see_no_evil:"},
+		{"zero width joiner sequence", "👩‍🚀", ":see_no_evil:", "👩‍🚀!", ":see_no_evil:!"},
+		{"emoji inside a zero width joiner sequence", "👩", ":see_no_evil:", "👩‍💻", ":see_no_evil:"},
+		{"emoji sequence", "🍆💦", ":see_no_evil:", "🍆 💦", ":see_no_evil:"},
+		{"emoji sequence interrupted by text", "🍆💦", ":see_no_evil:", "🍆 and 💦", "🍆 and 💦"},
+		{"custom emoji", ":party_parrot_rude:", ":see_no_evil:", "look :party_parrot_rude:", "look :see_no_evil:"},
+		{"other emoji are kept", "🖕", ":see_no_evil:", "👍 :thumbsup:", "👍 :thumbsup:"},
+		{"words and emoji", "fuck,🖕", "🙈", "fuck 🖕", "**** 🙈"},
+		{"masked without a replacement", "🖕", "", "🖕🏿", "**"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: tc.wordList, EmojiReplacement: tc.replacement})
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// decoder produces an alternate view of a text for matching, or false if the text does not look
+// encoded that way
+type decoder func(text string) (normalizedText, bool)
+
+// decoders maps the encodings accepted by the Decoders setting to their decoder
+var decoders = map[string]decoder{
+	"reversed":    decodeReversed,
+	"rot13":       decodeROT13,
+	"upside-down": decodeUpsideDown,
+	"mirrored":    decodeMirrored,
+	"base64":      decodeBase64,
+}
+
+// upsideDownLetters maps the letters of upside-down text back to the letters they were flipped from
+var upsideDownLetters = map[rune]rune{
+	'ɐ': 'a', 'q': 'b', 'ɔ': 'c', 'p': 'd', 'ǝ': 'e', 'ɟ': 'f', 'ƃ': 'g', 'ɥ': 'h',
+	'ᴉ': 'i', 'ı': 'i', 'ɾ': 'j', 'ʞ': 'k', 'ן': 'l', 'ɯ': 'm', 'u': 'n', 'd': 'p',
+	'b': 'q', 'ɹ': 'r', 'ʇ': 't', 'n': 'u', 'ʌ': 'v', 'ʍ': 'w', 'ʎ': 'y',
+	'∀': 'A', 'Ɔ': 'C', 'Ǝ': 'E', 'Ⅎ': 'F', '⅁': 'G', 'ſ': 'J', 'Ꞁ': 'L', 'Ԁ': 'P',
+	'ᴚ': 'R', '⊥': 'T', '∩': 'U', 'Λ': 'V', '⅄': 'Y',
+}
+
+// mirroredLetters maps the letters of mirrored text back to the letters they were mirrored from
+var mirroredLetters = map[rune]rune{
+	'ɒ': 'a', 'd': 'b', 'ɔ': 'c', 'b': 'd', 'ɘ': 'e', 'ꟻ': 'f', 'ϱ': 'g', 'ʜ': 'h',
+	'į': 'j', 'ʞ': 'k', 'ᴎ': 'n', 'q': 'p', 'p': 'q', 'ᴙ': 'r', 'ƨ': 's', 'ƚ': 't',
+	'υ': 'u', 'γ': 'y', 'ƹ': 'z',
+	'ᙠ': 'B', 'Ɔ': 'C', 'ᗡ': 'D', 'Ǝ': 'E', 'Ⴑ': 'J', 'ꓘ': 'K', '⅃': 'L', 'И': 'N',
+	'ꟼ': 'P', 'Я': 'R', 'Ƨ': 'S', 'Ƹ': 'Z',
+}
+
+// base64SnippetRegex finds the short base64 snippets of a text
+var base64SnippetRegex = regexp.MustCompile(`[A-Za-z0-9+/]{6,256}={0,2}`)
+
+// parseDecoders splits the Decoders setting into the encodings to decode
+func parseDecoders(setting string) ([]string, error) {
+	var encodings []string
+	for _, encoding := range splitWordList(setting) {
+		encoding = strings.ToLower(encoding)
+		if _, ok := decoders[encoding]; !ok {
+			return nil, fmt.Errorf("unsupported decoder %q", encoding)
+		}
+		encodings = append(encodings, encoding)
+	}
+	return encodings, nil
+}
+
+// decodeReversed reads the text backwards, e.g. "kcuf" as "fuck"
+func decodeReversed(text string) (normalizedText, bool) {
+	return reverseRunes(text, func(r rune) rune { return r }), true
+}
+
+// decodeROT13 rotates each Latin letter by 13 places, e.g. "shpx" to "fuck"
+func decodeROT13(text string) (normalizedText, bool) {
+	return normalizeRunes(text, func(r rune) string {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return string('a' + (r-'a'+13)%26)
+		case r >= 'A' && r <= 'Z':
+			return string('A' + (r-'A'+13)%26)
+		}
+		return string(r)
+	}), true
+}
+
+// decodeUpsideDown flips upside-down text back, e.g. "ʞɔnɟ" to "fuck"
+func decodeUpsideDown(text string) (normalizedText, bool) {
+	return decodeFlipped(text, upsideDownLetters)
+}
+
+// decodeMirrored mirrors mirrored text back, e.g. "ʞɔυꟻ" to "fuck"
+func decodeMirrored(text string) (normalizedText, bool) {
+	return decodeFlipped(text, mirroredLetters)
+}
+
+// decodeFlipped maps flipped letters back and reverses the text, which is written right to left
+// once flipped. Texts without any flipped letter outside of ASCII are left alone, since most
+// ASCII letters are also flipped forms of other ASCII letters.
+func decodeFlipped(text string, letters map[rune]rune) (normalizedText, bool) {
+	flipped := false
+	for _, r := range text {
+		if _, ok := letters[r]; ok && r > unicode.MaxASCII {
+			flipped = true
+			break
+		}
+	}
+	if !flipped {
+		return normalizedText{}, false
+	}
+
+	return reverseRunes(text, func(r rune) rune {
+		if letter, ok := letters[r]; ok {
+			return letter
+		}
+		return r
+	}), true
+}
+
+// reverseRunes maps each rune of the text and reverses their order
+func reverseRunes(text string, mapRune func(r rune) rune) normalizedText {
+	var builder normalizedTextBuilder
+	for end := len(text); end > 0; {
+		r, size := utf8.DecodeLastRuneInString(text[:end])
+		builder.add(string(mapRune(r)), end-size, end)
+		end -= size
+	}
+	return builder.build()
+}
+
+// decodeBase64 decodes the short base64 snippets of the text that decode to printable text,
+// e.g. "ZnVjaw==" to "fuck"
+func decodeBase64(text string) (normalizedText, bool) {
+	var builder normalizedTextBuilder
+	for _, match := range base64SnippetRegex.FindAllStringIndex(text, -1) {
+		snippet := text[match[0]:match[1]]
+
+		decoded, err := base64.StdEncoding.DecodeString(snippet)
+		if err != nil {
+			decoded, err = base64.RawStdEncoding.DecodeString(snippet)
+		}
+		if err != nil || !isPrintableText(decoded) {
+			continue
+		}
+
+		builder.add(string(decoded), match[0], match[1])
+		builder.add(" ", match[1], match[1])
+	}
+
+	view := builder.build()
+	return view, view.text != ""
+}
+
+// isPrintableText checks if decoded bytes are valid UTF-8 text without control characters
+func isPrintableText(decoded []byte) bool {
+	if len(decoded) == 0 || !utf8.Valid(decoded) {
+		return false
+	}
+	for _, r := range string(decoded) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isEncodedMatch checks if the original words of a match in a decoded text were encoded on purpose,
+// rather than being innocent words that decode to a bad word, such as "dog" reversed or "uber" in
+// ROT13. Common words and words that match as they are written are not taken as encoded.
+func isEncodedMatch(original string, regex *regexp.Regexp) bool {
+	if regex.MatchString(original) {
+		return false
+	}
+	for _, word := range stemmedWordRegex.FindAllString(original, -1) {
+		if isCommonWord(word) {
+			return false
+		}
+	}
+	return true
+}
+
+// detectEncodedWords matches the bad words against each configured decoding of the text. Hits are
+// reported with the encoding used and located at their span in the original text.
+func (p *Plugin) detectEncodedWords(text string, terms *termSet) []detection {
+	var detected []detection
+
+	regex := terms.asciiWordsRegex
+	if regex == nil {
+		return detected
+	}
+
+	for _, encoding := range p.getDecoders() {
+		view, ok := decoders[encoding](text)
+		if !ok {
+			continue
+		}
+		for _, match := range regex.FindAllStringIndex(view.text, -1) {
+			if match[1] > match[0] {
+				start, end := view.originalSpan(match[0], match[1])
+				if !isEncodedMatch(text[start:end], regex) {
+					continue
+				}
+				d := newDetection(text, start, end)
+				d.Strategy = "decoded"
+				d.Encoding = encoding
+				d.Term = view.text[match[0]:match[1]]
+				detected = append(detected, d)
+			}
+		}
+	}
+
+	return detected
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestDecoders(t *testing.T) {
+	testCases := []struct {
+		name           string
+		wordList       string
+		decoders       string
+		input          string
+		expectedOutput string
+	}{
+		{"decoders disabled by default", "fuck", "", "kcuf shpx", "kcuf shpx"},
+		{"reversed", "fuck", "reversed", "kcuf you", "**** you"},
+		{"reversed phrase", "fuck you", "reversed", "uoy kcuf", "********"},
+		{"reversed common word", "God", "reversed", "my dog is cute", "my dog is cute"},
+		{"reversed word that matches as written", "fuck,kcuf", "reversed", "kcuf", "****"},
+		{"rot13", "fuck", "rot13", "shpx this", "**** this"},
+		{"upside-down", "fuck", "upside-down", "ʞɔnɟ", "****"},
+		{"upside-down inside a message", "fuck", "upside-down", "well ʞɔnɟ", "well ****"},
+		{"mirrored", "fuck", "mirrored", "ʞɔυꟻ", "****"},
+		{"base64", "fuck", "base64", "decode ZnVjaw== please", "decode ******** please"},
+		{"base64 without padding", "fuck", "base64", "ZnVjaw", "******"},
+		{"only configured decoders", "fuck", "reversed", "shpx kcuf", "shpx ****"},
+		{"several decoders", "fuck", "reversed, ROT13", "shpx kcuf", "**** ****"},
+		{"plain text is left alone", "fuck", "upside-down, mirrored, base64", "the quick brown fox", "the quick brown fox"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: tc.wordList, Decoders: tc.decoders})
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+
+	t.Run("common words are not decoded", func(t *testing.T) {
+		p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: defaultBadWordsList(t), Decoders: "reversed, rot13"})
+
+		rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: "I bought some pens at uber"})
+		assert.Empty(t, s)
+		assert.Equal(t, "I bought some pens at uber", rpost.Message)
+	})
+
+	t.Run("encoding is reported", func(t *testing.T) {
+		p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: "fuck", Decoders: "rot13"})
+
+		detected := p.detectEncodedWords("oh shpx", p.getTerms())
+		require.Len(t, detected, 1)
+		assert.Equal(t, "shpx", detected[0].Word)
+		assert.Equal(t, "decoded", detected[0].Strategy)
+		assert.Equal(t, "rot13", detected[0].Encoding)
+	})
+
+	t.Run("unsupported decoder is rejected", func(t *testing.T) {
+		p := &Plugin{
+			configuration: &configuration{
+				BadWordsList: "fuck",
+				Decoders:     "enigma",
+			},
+		}
+		assert.Error(t, p.compileWordRegexes(p.getConfiguration().BadWordsList))
+	})
+}
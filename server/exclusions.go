@@ -0,0 +1,162 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+var (
+	// mentionRegex finds @mentions, whose usernames may end with a period that is not part of them
+	mentionRegex = regexp.MustCompile(`(?i)\B@([a-z0-9.\-_]+)`)
+	// channelLinkRegex finds ~channel links
+	channelLinkRegex = regexp.MustCompile(`(?i)\B~([a-z0-9\-_]+)`)
+	// hashtagRegex finds hashtags
+	hashtagRegex = regexp.MustCompile(`\B#([\p{L}\p{N}][\p{L}\p{N}.\-_]*)`)
+)
+
+// exclusion is a span of a message that is never censored, such as the mention of an existing user
+type exclusion struct {
+	Start, End int
+	// Shortcode marks the span of an emoji shortcode, which emoji terms may still match
+	Shortcode bool
+}
+
+// identifierLookup answers whether users, channels and emoji exist, looking each one up once
+type identifierLookup struct {
+	api       plugin.API
+	channelID string
+	teamID    *string
+	known     map[string]bool
+}
+
+// user checks if a user with the given username exists
+func (l *identifierLookup) user(username string) bool {
+	key := "@" + strings.ToLower(username)
+	if known, ok := l.known[key]; ok {
+		return known
+	}
+	user, err := l.api.GetUserByUsername(strings.ToLower(username))
+	l.known[key] = err == nil && user != nil
+	return l.known[key]
+}
+
+// channel checks if a channel with the given name exists in the team of the post
+func (l *identifierLookup) channel(name string) bool {
+	key := "~" + strings.ToLower(name)
+	if known, ok := l.known[key]; ok {
+		return known
+	}
+
+	if l.teamID == nil {
+		teamID := ""
+		if channel, err := l.api.GetChannel(l.channelID); err == nil && channel != nil {
+			teamID = channel.TeamId
+		}
+		l.teamID = &teamID
+	}
+
+	l.known[key] = false
+	if *l.teamID != "" {
+		channel, err := l.api.GetChannelByName(*l.teamID, strings.ToLower(name), false)
+		l.known[key] = err == nil && channel != nil
+	}
+	return l.known[key]
+}
+
+// emoji checks if a system or custom emoji with the given name exists
+func (l *identifierLookup) emoji(name string) bool {
+	name = strings.ToLower(name)
+	if _, ok := model.SystemEmojis[name]; ok {
+		return true
+	}
+
+	key := ":" + name + ":"
+	if known, ok := l.known[key]; ok {
+		return known
+	}
+	emoji, err := l.api.GetEmojiByName(name)
+	l.known[key] = err == nil && emoji != nil
+	return l.known[key]
+}
+
+// findExclusions finds the mentions of existing users, links to existing channels, shortcodes of
+// existing emoji and hashtags of known user or channel names in a message posted in a channel, as
+// enabled in the configuration
+func (p *Plugin) findExclusions(channelID, message string, config *configuration) []exclusion {
+	var exclusions []exclusion
+	if p.API == nil {
+		return exclusions
+	}
+
+	lookup := &identifierLookup{api: p.API, channelID: channelID, known: map[string]bool{}}
+
+	if config.ExcludeMentions {
+		for _, match := range mentionRegex.FindAllStringSubmatchIndex(message, -1) {
+			// Try the username with and without the trailing periods that may end a sentence
+			username := message[match[2]:match[3]]
+			for username != "" {
+				if lookup.user(username) {
+					exclusions = append(exclusions, exclusion{Start: match[0], End: match[2] + len(username)})
+					break
+				}
+				if !strings.HasSuffix(username, ".") {
+					break
+				}
+				username = strings.TrimSuffix(username, ".")
+			}
+		}
+	}
+
+	if config.ExcludeChannelLinks {
+		for _, match := range channelLinkRegex.FindAllStringSubmatchIndex(message, -1) {
+			if lookup.channel(message[match[2]:match[3]]) {
+				exclusions = append(exclusions, exclusion{Start: match[0], End: match[1]})
+			}
+		}
+	}
+
+	if config.ExcludeEmojiShortcodes {
+		for _, match := range emojiShortcodeRegex.FindAllStringIndex(message, -1) {
+			if lookup.emoji(strings.Trim(message[match[0]:match[1]], ":")) {
+				exclusions = append(exclusions, exclusion{Start: match[0], End: match[1], Shortcode: true})
+			}
+		}
+	}
+
+	if config.ExcludeHashtags {
+		for _, match := range hashtagRegex.FindAllStringSubmatchIndex(message, -1) {
+			name := strings.TrimRight(message[match[2]:match[3]], ".-_")
+			if lookup.user(name) || lookup.channel(name) {
+				exclusions = append(exclusions, exclusion{Start: match[0], End: match[2] + len(name)})
+			}
+		}
+	}
+
+	return exclusions
+}
+
+// removeExcludedDetections drops the detections that overlap an exclusion. Emoji shortcodes are
+// only excluded from word matching, so that emoji terms can still match them.
+func removeExcludedDetections(detections []detection, exclusions []exclusion) []detection {
+	if len(exclusions) == 0 {
+		return detections
+	}
+
+	var kept []detection
+	for _, d := range detections {
+		excluded := false
+		for _, e := range exclusions {
+			if d.Start < e.End && e.Start < d.End && !(e.Shortcode && d.Strategy == "emoji") {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestExclusions(t *testing.T) {
+	notFound := model.NewAppError("test", "not_found", nil, "", http.StatusNotFound)
+
+	newPlugin := func(t *testing.T, config *configuration) *Plugin {
+		config.CensorCharacter = "*"
+		p, api := newTestPluginWithAPI(t, config)
+		api.On("GetUserByUsername", "dick.smith").Return(&model.User{Username: "dick.smith"}, nil).Maybe()
+		api.On("GetUserByUsername", mock.Anything).Return(nil, notFound).Maybe()
+		api.On("GetChannel", "channel-id").Return(&model.Channel{Id: "channel-id", TeamId: "team-id"}, nil).Maybe()
+		api.On("GetChannelByName", "team-id", "shit-happens", false).Return(&model.Channel{Name: "shit-happens"}, nil).Maybe()
+		api.On("GetChannelByName", "team-id", mock.Anything, false).Return(nil, notFound).Maybe()
+		api.On("GetEmojiByName", "fuck_this").Return(&model.Emoji{Name: "fuck_this"}, nil).Maybe()
+		api.On("GetEmojiByName", mock.Anything).Return(nil, notFound).Maybe()
+		return p
+	}
+
+	excludeAll := configuration{
+		ExcludeMentions:        true,
+		ExcludeChannelLinks:    true,
+		ExcludeEmojiShortcodes: true,
+		ExcludeHashtags:        true,
+	}
+
+	testCases := []struct {
+		name           string
+		config         configuration
+		wordList       string
+		input          string
+		expectedOutput string
+	}{
+		{"mention of an existing user", excludeAll, "dick", "hi @dick.smith, dick", "hi @dick.smith, ****"},
+		{"mention at the end of a sentence", excludeAll, "dick", "ask @dick.smith.", "ask @dick.smith."},
+		{"mention of an unknown user", excludeAll, "dick", "hi @dick", "hi @****"},
+		{"mentions excluded only when enabled", configuration{}, "dick", "hi @dick.smith", "hi @****.smith"},
+		{"link to an existing channel", excludeAll, "shit", "see ~shit-happens", "see ~shit-happens"},
+		{"link to an unknown channel", excludeAll, "shit", "see ~shit-show", "see ~****-show"},
+		{"shortcode of a custom emoji", excludeAll, "fuck", ":fuck_this: fuck", ":fuck_this: ****"},
+		{"shortcode of an unknown emoji", excludeAll, "fuck", ":fuck-that:", ":****-that:"},
+		{"emoji terms still match excluded shortcodes", excludeAll, ":fuck_this:", ":fuck_this:", "***********"},
+		{"hashtag of a known name", excludeAll, "shit", "#shit-happens", "#shit-happens"},
+		{"hashtag of an unknown name", excludeAll, "shit", "#shit", "#****"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.config
+			config.BadWordsList = tc.wordList
+			p := newPlugin(t, &config)
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{ChannelId: "channel-id", Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+}
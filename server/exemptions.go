@@ -0,0 +1,160 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	// exemptionCacheTTL is how long the exemption of a user in a channel is remembered, so that
+	// group memberships and roles are not looked up for every post
+	exemptionCacheTTL = 5 * time.Minute
+
+	// exemptionCacheMaxEntries bounds the number of cached exemptions
+	exemptionCacheMaxEntries = 10000
+)
+
+// exemptionCache remembers whether users are exempt from filtering in a channel
+type exemptionCache = expiringCache[bool]
+
+// newExemptionCache creates an empty exemption cache
+func newExemptionCache() *exemptionCache {
+	return newExpiringCache[bool](exemptionCacheTTL, exemptionCacheMaxEntries)
+}
+
+// resetExemptionCache forgets every cached exemption
+func (p *Plugin) resetExemptionCache() {
+	p.configurationLock.Lock()
+	defer p.configurationLock.Unlock()
+	p.exemptionCache = newExemptionCache()
+}
+
+// isExempt checks if a user is exempt from filtering in a channel, by user ID or username, group
+// membership, or team or channel role
+func (p *Plugin) isExempt(userID, channelID string) bool {
+	configuration := p.getConfiguration()
+	if userID == "" || (configuration.ExemptUsers == "" && configuration.ExemptGroups == "" && configuration.ExemptRoles == "") {
+		return false
+	}
+
+	cache := p.getExemptionCache()
+	key := userID + ":" + channelID
+	if exempt, ok := cache.get(key); ok {
+		return exempt
+	}
+
+	exempt, ok := p.resolveExemption(userID, channelID, configuration)
+	if ok {
+		cache.set(key, exempt)
+	}
+	return exempt
+}
+
+// resolveExemption looks up whether a user is exempt from filtering in a channel. Lookups that
+// fail are not exempt, and reported as not ok so that they are not cached.
+func (p *Plugin) resolveExemption(userID, channelID string, config *configuration) (exempt, ok bool) {
+	ok = true
+
+	users := splitWordList(config.ExemptUsers)
+	if slices.Contains(users, userID) {
+		return true, true
+	}
+	if slices.ContainsFunc(users, func(user string) bool { return !model.IsValidId(user) }) {
+		user, appErr := p.API.GetUser(userID)
+		if appErr != nil {
+			p.API.LogWarn("Failed to get a user to resolve its exemption", "user_id", userID, "err", appErr.Error())
+			ok = false
+		} else if slices.ContainsFunc(users, func(username string) bool {
+			return strings.EqualFold(strings.TrimPrefix(username, "@"), user.Username)
+		}) {
+			return true, true
+		}
+	}
+
+	if groups := splitWordList(config.ExemptGroups); len(groups) > 0 {
+		memberships, appErr := p.API.GetGroupsForUser(userID)
+		if appErr != nil {
+			p.API.LogWarn("Failed to get the groups of a user to resolve its exemption", "user_id", userID, "err", appErr.Error())
+			ok = false
+		}
+		for _, group := range memberships {
+			if slices.ContainsFunc(groups, func(name string) bool { return isGroup(group, name) }) {
+				return true, true
+			}
+		}
+	}
+
+	if roles := splitWordList(config.ExemptRoles); len(roles) > 0 && channelID != "" {
+		memberRoles, rolesOK := p.memberRoles(userID, channelID)
+		ok = ok && rolesOK
+		for _, role := range roles {
+			if slices.ContainsFunc(memberRoles, func(memberRole string) bool { return strings.EqualFold(memberRole, role) }) {
+				return true, true
+			}
+		}
+	}
+
+	return false, ok
+}
+
+// isGroup checks if a group has the given name, display name or ID
+func isGroup(group *model.Group, name string) bool {
+	name = strings.TrimPrefix(name, "@")
+	if group.Id == name || strings.EqualFold(group.DisplayName, name) {
+		return true
+	}
+	return group.Name != nil && strings.EqualFold(*group.Name, name)
+}
+
+// memberRoles returns the roles of a user in a channel and its team, including the roles granted
+// by their schemes
+func (p *Plugin) memberRoles(userID, channelID string) ([]string, bool) {
+	ok := true
+	var roles []string
+
+	member, appErr := p.API.GetChannelMember(channelID, userID)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get a channel member to resolve its exemption", "user_id", userID, "channel_id", channelID, "err", appErr.Error())
+		ok = false
+	} else {
+		roles = append(roles, strings.Fields(member.Roles)...)
+		roles = appendSchemeRoles(roles, member.SchemeGuest, member.SchemeUser, member.SchemeAdmin,
+			model.ChannelGuestRoleId, model.ChannelUserRoleId, model.ChannelAdminRoleId)
+	}
+
+	channel, appErr := p.API.GetChannel(channelID)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get a channel to resolve an exemption", "channel_id", channelID, "err", appErr.Error())
+		return roles, false
+	}
+	if channel.TeamId == "" {
+		return roles, ok
+	}
+
+	teamMember, appErr := p.API.GetTeamMember(channel.TeamId, userID)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get a team member to resolve its exemption", "user_id", userID, "team_id", channel.TeamId, "err", appErr.Error())
+		return roles, false
+	}
+	roles = append(roles, strings.Fields(teamMember.Roles)...)
+	roles = appendSchemeRoles(roles, teamMember.SchemeGuest, teamMember.SchemeUser, teamMember.SchemeAdmin,
+		model.TeamGuestRoleId, model.TeamUserRoleId, model.TeamAdminRoleId)
+	return roles, ok
+}
+
+// appendSchemeRoles appends the roles granted by the scheme of a team or channel
+func appendSchemeRoles(roles []string, guest, user, admin bool, guestRole, userRole, adminRole string) []string {
+	if guest {
+		roles = append(roles, guestRole)
+	}
+	if user {
+		roles = append(roles, userRole)
+	}
+	if admin {
+		roles = append(roles, adminRole)
+	}
+	return roles
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+)
+
+func TestExemptions(t *testing.T) {
+	exemptID := model.NewId()
+	userID := model.NewId()
+	channelID := model.NewId()
+	teamID := model.NewId()
+
+	newPlugin := func(t *testing.T, config *configuration) (*Plugin, *plugintest.API) {
+		config.CensorCharacter = "*"
+		config.BadWordsList = "fuck"
+		p, api := newTestPluginWithAPI(t, config)
+
+		api.On("LogWarn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+		api.On("LogWarn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+		api.On("GetChannel", channelID).Return(&model.Channel{Id: channelID, TeamId: teamID, Type: model.ChannelTypeOpen}, nil)
+		return p, api
+	}
+
+	post := func(userID string) *model.Post {
+		return &model.Post{UserId: userID, ChannelId: channelID, Message: "what the fuck"}
+	}
+
+	testCases := []struct {
+		name     string
+		config   *configuration
+		mocks    func(api *plugintest.API)
+		expected string
+	}{
+		{
+			name:     "user ID",
+			config:   &configuration{ExemptUsers: "someone, " + userID},
+			expected: "what the fuck",
+		},
+		{
+			name:   "username",
+			config: &configuration{ExemptUsers: exemptID + ", @HR.Alice"},
+			mocks: func(api *plugintest.API) {
+				api.On("GetUser", userID).Return(&model.User{Id: userID, Username: "hr.alice"}, nil)
+			},
+			expected: "what the fuck",
+		},
+		{
+			name:   "other user",
+			config: &configuration{ExemptUsers: exemptID + ", hr.alice"},
+			mocks: func(api *plugintest.API) {
+				api.On("GetUser", userID).Return(&model.User{Id: userID, Username: "bob"}, nil)
+			},
+			expected: "what the ****",
+		},
+		{
+			name:   "group name",
+			config: &configuration{ExemptGroups: "legal, trust-and-safety"},
+			mocks: func(api *plugintest.API) {
+				api.On("GetGroupsForUser", userID).Return([]*model.Group{{Id: model.NewId(), Name: model.NewPointer("legal"), DisplayName: "Legal"}}, nil)
+			},
+			expected: "what the fuck",
+		},
+		{
+			name:   "group display name",
+			config: &configuration{ExemptGroups: "Trust and Safety"},
+			mocks: func(api *plugintest.API) {
+				api.On("GetGroupsForUser", userID).Return([]*model.Group{{Id: model.NewId(), DisplayName: "Trust and Safety"}}, nil)
+			},
+			expected: "what the fuck",
+		},
+		{
+			name:   "team role",
+			config: &configuration{ExemptRoles: "team_admin"},
+			mocks: func(api *plugintest.API) {
+				api.On("GetChannelMember", channelID, userID).Return(&model.ChannelMember{Roles: "channel_user", SchemeUser: true}, nil)
+				api.On("GetTeamMember", teamID, userID).Return(&model.TeamMember{SchemeUser: true, SchemeAdmin: true}, nil)
+			},
+			expected: "what the fuck",
+		},
+		{
+			name:   "custom channel role",
+			config: &configuration{ExemptRoles: "case_handler"},
+			mocks: func(api *plugintest.API) {
+				api.On("GetChannelMember", channelID, userID).Return(&model.ChannelMember{Roles: "channel_user case_handler"}, nil)
+				api.On("GetTeamMember", teamID, userID).Return(&model.TeamMember{SchemeUser: true}, nil)
+			},
+			expected: "what the fuck",
+		},
+		{
+			name:   "role names are compared case insensitively",
+			config: &configuration{ExemptRoles: "Case_Handler"},
+			mocks: func(api *plugintest.API) {
+				api.On("GetChannelMember", channelID, userID).Return(&model.ChannelMember{Roles: "channel_user case_handler"}, nil)
+				api.On("GetTeamMember", teamID, userID).Return(&model.TeamMember{SchemeUser: true}, nil)
+			},
+			expected: "what the fuck",
+		},
+		{
+			name:   "other roles",
+			config: &configuration{ExemptRoles: "team_admin, channel_admin"},
+			mocks: func(api *plugintest.API) {
+				api.On("GetChannelMember", channelID, userID).Return(&model.ChannelMember{SchemeUser: true}, nil)
+				api.On("GetTeamMember", teamID, userID).Return(&model.TeamMember{SchemeUser: true}, nil)
+			},
+			expected: "what the ****",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, api := newPlugin(t, tc.config)
+			if tc.mocks != nil {
+				tc.mocks(api)
+			}
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, post(userID))
+
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expected)
+			assert.Empty(t, s)
+			assert.Equal(t, tc.expected, rpost.Message)
+		})
+	}
+
+	t.Run("exemptions are cached", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{ExemptGroups: "legal"})
+		p.resetExemptionCache()
+		api.On("GetGroupsForUser", userID).Return([]*model.Group{{Id: model.NewId(), Name: model.NewPointer("legal")}}, nil).Once()
+
+		for i := 0; i < 3; i++ {
+			rpost, _ := p.MessageWillBePosted(&plugin.Context{}, post(userID))
+			assert.Equal(t, "what the fuck", rpost.Message)
+		}
+		api.AssertNumberOfCalls(t, "GetGroupsForUser", 1)
+
+		// A new configuration forgets the cached exemptions
+		config := p.getConfiguration()
+		api.On("LoadPluginConfiguration", mock.AnythingOfType("*main.configuration")).Return(nil).Run(func(args mock.Arguments) {
+			*args.Get(0).(*configuration) = *config
+		})
+		require.NoError(t, p.OnConfigurationChange())
+		api.On("GetGroupsForUser", userID).Return([]*model.Group{}, nil).Once()
+		rpost, _ := p.MessageWillBePosted(&plugin.Context{}, post(userID))
+		assert.Equal(t, "what the ****", rpost.Message)
+	})
+
+	t.Run("the cache is bounded", func(t *testing.T) {
+		cache := newExemptionCache()
+		cache.entries["expired"] = expiringCacheEntry[bool]{value: true, expires: time.Now().Add(-time.Minute)}
+		for i := 1; i < exemptionCacheMaxEntries; i++ {
+			cache.set(fmt.Sprint(i), true)
+		}
+		require.Len(t, cache.entries, exemptionCacheMaxEntries)
+		cache.entries["1"] = expiringCacheEntry[bool]{value: true, expires: time.Now().Add(time.Second)}
+
+		cache.set("pruned", true)
+		assert.Len(t, cache.entries, exemptionCacheMaxEntries)
+		assert.NotContains(t, cache.entries, "expired")
+
+		cache.set("evicted", true)
+		assert.Len(t, cache.entries, exemptionCacheMaxEntries)
+		assert.NotContains(t, cache.entries, "1", "the entry that expires first is evicted")
+		_, ok := cache.get("evicted")
+		assert.True(t, ok)
+	})
+
+	t.Run("failed lookups are not cached", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{ExemptGroups: "legal"})
+		api.On("GetGroupsForUser", userID).Return(nil, model.NewAppError("GetGroupsForUser", "not_licensed", nil, "", 501))
+
+		for i := 0; i < 2; i++ {
+			rpost, _ := p.MessageWillBePosted(&plugin.Context{}, post(userID))
+			assert.Equal(t, "what the ****", rpost.Message)
+		}
+		api.AssertNumberOfCalls(t, "GetGroupsForUser", 2)
+	})
+
+	t.Run("no exemptions configured", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{})
+
+		rpost, _ := p.MessageWillBePosted(&plugin.Context{}, post(userID))
+
+		assert.Equal(t, "what the ****", rpost.Message)
+		api.AssertNotCalled(t, "GetUser", mock.Anything)
+		api.AssertNotCalled(t, "GetChannel", mock.Anything)
+	})
+
+	t.Run("file uploads", func(t *testing.T) {
+		p, _ := newPlugin(t, &configuration{FilterFileUploads: true, ExemptUsers: userID})
+
+		info := &model.FileInfo{Name: "fuck.txt", CreatorId: userID, ChannelId: channelID}
+		rinfo, s := p.FileWillBeUploaded(&plugin.Context{}, info, bytes.NewReader([]byte("fuck")), &bytes.Buffer{})
+
+		assert.Nil(t, rinfo)
+		assert.Empty(t, s)
+	})
+}
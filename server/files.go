@@ -0,0 +1,276 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+const (
+	// fileNameActionRename censors the bad words of file names, which are otherwise rejected
+	fileNameActionRename = "rename"
+
+	// defaultFileScanMaxSizeKB is the size up to which the contents of files are scanned when none
+	// is configured
+	defaultFileScanMaxSizeKB = 1024
+
+	// fileNameCensorCharacter masks the bad words of file names when the censor character cannot
+	// be used in file names, such as "*"
+	fileNameCensorCharacter = "_"
+)
+
+// fileTextExtractors extract the text of the supported file formats, by lower case extension
+var fileTextExtractors = map[string]func(data []byte) (string, error){
+	".txt":  extractPlainText,
+	".md":   extractPlainText,
+	".csv":  extractPlainText,
+	".log":  extractPlainText,
+	".json": extractJSONText,
+	".docx": extractDocxText,
+}
+
+// docxTextParts matches the parts of a .docx document holding text the user sees
+var docxTextParts = []string{"word/document.xml", "word/header*.xml", "word/footer*.xml", "word/footnotes.xml", "word/endnotes.xml", "word/comments.xml"}
+
+// extractPlainText returns the text of a text file, which must be valid UTF-8
+func extractPlainText(data []byte) (string, error) {
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("file is not valid UTF-8 text")
+	}
+	return string(data), nil
+}
+
+// extractJSONText returns the keys and string values of a JSON document, one per line, so that
+// escaped characters are decoded. Invalid JSON is scanned as plain text.
+func extractJSONText(data []byte) (string, error) {
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return extractPlainText(data)
+	}
+
+	var builder strings.Builder
+	var walk func(value any)
+	walk = func(value any) {
+		switch v := value.(type) {
+		case string:
+			builder.WriteString(v)
+			builder.WriteByte('\n')
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]any:
+			for key, item := range v {
+				builder.WriteString(key)
+				builder.WriteByte('\n')
+				walk(item)
+			}
+		}
+	}
+	walk(document)
+	return builder.String(), nil
+}
+
+// extractDocxText returns the text of the body, headers, footers, notes and comments of a .docx
+// document, one paragraph per line
+func extractDocxText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open docx archive: %w", err)
+	}
+
+	var builder strings.Builder
+	for _, file := range archive.File {
+		if !isDocxTextPart(file.Name) {
+			continue
+		}
+		part, err := file.Open()
+		if err != nil {
+			return "", fmt.Errorf("failed to open docx part %s: %w", file.Name, err)
+		}
+		err = extractWordprocessingText(part, &builder)
+		part.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read docx part %s: %w", file.Name, err)
+		}
+	}
+	return builder.String(), nil
+}
+
+// isDocxTextPart checks if a part of a .docx archive holds text the user sees
+func isDocxTextPart(name string) bool {
+	for _, pattern := range docxTextParts {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// extractWordprocessingText writes the text runs of a WordprocessingML part, ending each paragraph
+// with a new line
+func extractWordprocessingText(part io.Reader, builder *strings.Builder) error {
+	decoder := xml.NewDecoder(part)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				builder.WriteByte('\t')
+			case "br":
+				builder.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				builder.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				builder.Write(t)
+			}
+		}
+	}
+}
+
+// sanitizeFileName replaces the characters that are not allowed in file names
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return []rune(fileNameCensorCharacter)[0]
+		}
+		return r
+	}, name)
+}
+
+// detectFileName detects the bad words of a file name that are filtered by the term policy
+func (p *Plugin) detectFileName(name string, policy *termPolicy) []detection {
+	return p.detectScoped(func(terms *termSet) []detection {
+		detections := p.detectPlainProfanityWords(separatorsToSpaces(name, "_-."), terms)
+		for i, d := range detections {
+			detections[i].Word = name[d.Start:d.End]
+			// File names are not markdown: they are neither struck through nor escaped
+			detections[i].Verbatim = true
+		}
+		return detections
+	}, policy)
+}
+
+// censorFileName censors the bad words of a file name in the censor style. The censor character is
+// unescaped, as file names are not markdown, and replaced if file names cannot contain it.
+func (p *Plugin) censorFileName(name string, detections []detection, config *configuration) string {
+	censor := p.newCensorer(config)
+	censor.character = unescapeMarkdown(censor.character)
+	if sanitizeFileName(censor.character) != censor.character {
+		censor.character = fileNameCensorCharacter
+	}
+	return sanitizeFileName(censorDetections(name, detections, censor))
+}
+
+// FileWillBeUploaded checks the names of uploaded files and the text of the supported file formats.
+// Profane file names are renamed or rejected as configured. Files whose text is profane are always
+// rejected, as their contents cannot be censored.
+func (p *Plugin) FileWillBeUploaded(_ *plugin.Context, info *model.FileInfo, file io.Reader, _ io.Writer) (*model.FileInfo, string) {
+	configuration := p.getConfiguration()
+	if !configuration.FilterFileUploads || info == nil || p.isExempt(info.CreatorId, info.ChannelId) {
+		return nil, ""
+	}
+
+	// Layer the policies of the channel and uploader over the installation policy
+	configuration = p.scopeConfiguration(configuration, &model.Post{UserId: info.CreatorId, ChannelId: info.ChannelId})
+	if configuration.exempt {
+		return nil, ""
+	}
+
+	// replacement is the renamed file info, if any
+	var replacement *model.FileInfo
+
+	if detections := p.detectFileName(info.Name, configuration.termPolicy); len(detections) > 0 {
+		p.logFileDetections(info, detections)
+		if configuration.FileNameAction != fileNameActionRename {
+			return nil, fmt.Sprintf("Profane word not allowed in file name: %s", strings.Join(detectedWords(detections), ", "))
+		}
+
+		renamed := p.censorFileName(info.Name, detections, configuration)
+		renamedInfo := *info
+		replacement = &renamedInfo
+		if strings.HasSuffix(replacement.Path, replacement.Name) {
+			replacement.Path = strings.TrimSuffix(replacement.Path, replacement.Name) + renamed
+		}
+		replacement.Name = renamed
+		replacement.Extension = strings.TrimPrefix(strings.ToLower(filepath.Ext(renamed)), ".")
+	}
+
+	extract, ok := fileTextExtractors[strings.ToLower(filepath.Ext(info.Name))]
+	maxSize := int64(configuration.FileScanMaxSizeKB)
+	if maxSize <= 0 {
+		maxSize = defaultFileScanMaxSizeKB
+	}
+	maxSize *= 1024
+	if !ok || info.Size > maxSize {
+		return replacement, ""
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil || int64(len(data)) > maxSize {
+		return replacement, ""
+	}
+	text, err := extract(data)
+	if err != nil {
+		p.logFileError(info, err)
+		return replacement, ""
+	}
+
+	detections := p.detectScoped(func(terms *termSet) []detection {
+		return p.detectPlainProfanityWords(text, terms)
+	}, configuration.termPolicy)
+	if len(detections) > 0 {
+		p.logFileDetections(info, detections)
+		return nil, fmt.Sprintf("Profane word not allowed in file: %s", strings.Join(detectedWords(detections), ", "))
+	}
+
+	return replacement, ""
+}
+
+// logFileDetections reports each detection of an uploaded file together with the strategy that
+// matched it
+func (p *Plugin) logFileDetections(info *model.FileInfo, detections []detection) {
+	if p.API == nil {
+		return
+	}
+	for _, d := range detections {
+		p.API.LogDebug("Profanity detected in file", "file_name", info.Name, "user_id", info.CreatorId, "word", d.Word, "strategy", d.Strategy)
+	}
+}
+
+// logFileError reports an uploaded file whose text could not be extracted
+func (p *Plugin) logFileError(info *model.FileInfo, err error) {
+	if p.API == nil {
+		return
+	}
+	p.API.LogDebug("Failed to extract the text of an uploaded file", "file_name", info.Name, "err", err.Error())
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+// newDocx creates a .docx document with a paragraph of text in its body and footer
+func newDocx(t *testing.T, body, footer string) []byte {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	parts := map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>` + body + `</w:t></w:r></w:p></w:body></w:document>`,
+		"word/footer1.xml":  `<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>` + footer + `</w:t></w:r></w:p></w:ftr>`,
+		"word/styles.xml":   `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:style><w:name w:val="shit"/></w:style></w:styles>`,
+	}
+	for name, content := range parts {
+		part, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	return buffer.Bytes()
+}
+
+func TestFileWillBeUploaded(t *testing.T) {
+	newPlugin := func(t *testing.T, config *configuration) *Plugin {
+		config.FilterFileUploads = true
+		if config.CensorCharacter == "" {
+			config.CensorCharacter = `\*`
+		}
+		if config.BadWordsList == "" {
+			config.BadWordsList = "fuck,shit"
+		}
+		return newTestPlugin(t, config)
+	}
+
+	upload := func(p *Plugin, name string, data []byte) (*model.FileInfo, string) {
+		info := &model.FileInfo{Name: name, Path: "data/" + name, Size: int64(len(data))}
+		return p.FileWillBeUploaded(&plugin.Context{}, info, bytes.NewReader(data), &bytes.Buffer{})
+	}
+
+	testCases := []struct {
+		name           string
+		config         configuration
+		fileName       string
+		data           []byte
+		expectedName   string
+		expectedReject bool
+	}{
+		{"clean file", configuration{}, "notes.txt", []byte("all good"), "", false},
+		{"profane file name is rejected", configuration{}, "fuck_you.png", nil, "", true},
+		{"profane file name is renamed", configuration{FileNameAction: "rename"}, "fuck_you.png", nil, "_____you.png", false},
+		{"renamed in the censor style", configuration{FileNameAction: "rename", CensorStyle: "first-letter"}, "fuck.png", nil, "f___.png", false},
+		{"renamed with a censor character allowed in file names", configuration{FileNameAction: "rename", CensorCharacter: "#"}, "fuck.png", nil, "####.png", false},
+		{"file names are not struck through", configuration{FileNameAction: "rename", CensorStyle: "strikethrough"}, "fuck.png", nil, "____.png", false},
+		{"renamed in the style of the category", configuration{FileNameAction: "rename", CensorCategoryStyles: "mild=placeholder", BadWordsList: "shit#category=mild"}, "shit-list.png", nil, "[censored]-list.png", false},
+		{"renamed with the replacement of the term", configuration{FileNameAction: "rename", BadWordsList: "shit#replace=stuff"}, "Shit.png", nil, "Stuff.png", false},
+		{"profane text file", configuration{}, "notes.txt", []byte("what the fuck"), "", true},
+		{"profane markdown file", configuration{}, "README.md", []byte("# Shit list"), "", true},
+		{"profane csv file", configuration{}, "data.csv", []byte("id,word\n1,shit"), "", true},
+		{"profane log file", configuration{FileNameAction: "rename"}, "app.log", []byte("ERROR fuck"), "", true},
+		{"escaped json string", configuration{}, "data.json", []byte(`{"word": "fuck"}`), "", true},
+		{"json key", configuration{}, "data.json", []byte(`{"shit": 1}`), "", true},
+		{"invalid json is scanned as text", configuration{}, "data.json", []byte(`{shit`), "", true},
+		{"profane docx body", configuration{}, "report.docx", newDocx(t, "what the fuck", "page 1"), "", true},
+		{"profane docx footer", configuration{}, "report.docx", newDocx(t, "report", "shit"), "", true},
+		{"docx styles are not scanned", configuration{}, "report.docx", newDocx(t, "report", "page 1"), "", false},
+		{"unsupported formats are not scanned", configuration{}, "image.png", []byte("fuck"), "", false},
+		{"files over the size limit are not scanned", configuration{FileScanMaxSizeKB: 1}, "big.txt", []byte(strings.Repeat("a", 1024) + " fuck"), "", false},
+		{"binary text files are not scanned", configuration{}, "notes.txt", []byte{0xff, 0xfe, 'f', 'u', 'c', 'k'}, "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.config
+			p := newPlugin(t, &config)
+
+			info, reason := upload(p, tc.fileName, tc.data)
+			if tc.expectedReject {
+				assert.Nil(t, info)
+				assert.Contains(t, reason, "Profane word not allowed")
+				return
+			}
+
+			assert.Empty(t, reason)
+			if tc.expectedName == "" {
+				assert.Nil(t, info, "unchanged files are not replaced")
+				return
+			}
+			require.NotNil(t, info)
+			assert.Equal(t, tc.expectedName, info.Name)
+			assert.Equal(t, "data/"+tc.expectedName, info.Path)
+		})
+	}
+
+	t.Run("the uploaded file info is not modified", func(t *testing.T) {
+		p := newPlugin(t, &configuration{FileNameAction: "rename"})
+
+		info := &model.FileInfo{Name: "fuck.png", Path: "data/fuck.png"}
+		renamed, reason := p.FileWillBeUploaded(&plugin.Context{}, info, bytes.NewReader(nil), &bytes.Buffer{})
+		assert.Empty(t, reason)
+		require.NotNil(t, renamed)
+		assert.Equal(t, "____.png", renamed.Name)
+		assert.Equal(t, "fuck.png", info.Name)
+		assert.Equal(t, "data/fuck.png", info.Path)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		p := newPlugin(t, &configuration{})
+		p.configuration.FilterFileUploads = false
+
+		info, reason := upload(p, "fuck.txt", []byte("fuck"))
+		assert.Nil(t, info)
+		assert.Empty(t, reason)
+	})
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// fuzzyTermLengthPerEdit is the number of characters a term needs per allowed edit beyond the
+	// first, e.g. 10 for two edits
+	fuzzyTermLengthPerEdit = 5
+
+	// fuzzyMinTermLength is the length of the shortest term and of the shortest word that are fuzzy
+	// matched. A single edit away from a shorter word are too many innocent words.
+	fuzzyMinTermLength = 4
+
+	// fuzzyAnyEditMinTermLength is the length of the shortest term any edit is matched in. Shorter
+	// terms only match typos that swap two letters or add or drop a vowel, e.g. "fcuk", "sheit" or
+	// "bastrd", as substituting a letter or adding or dropping a consonant mostly makes another
+	// word, e.g. "walker" for "wanker" or "sitting" for "shitting".
+	fuzzyAnyEditMinTermLength = 10
+)
+
+// bkTree indexes the terms by edit distance, so that every term within a given distance of a
+// word can be found without comparing the word to each term
+type bkTree struct {
+	root *bkTreeNode
+	// maxDistance is the largest distance any term of the tree may be matched at
+	maxDistance int
+}
+
+// bkTreeNode is a term of the tree together with its children, keyed by their distance to the term
+type bkTreeNode struct {
+	word        string
+	maxDistance int
+	children    map[int]*bkTreeNode
+}
+
+// fuzzyMatch is a term found within its allowed distance of a word
+type fuzzyMatch struct {
+	Word     string
+	Distance int
+}
+
+// fuzzyMaxDistance returns the number of edits allowed for a term, one and then one more for every
+// fuzzyTermLengthPerEdit characters from the second, capped by the configured maximum
+func fuzzyMaxDistance(word string, maxDistance int) int {
+	distance := max(utf8.RuneCountInString(word)/fuzzyTermLengthPerEdit, 1)
+	if distance > maxDistance {
+		return maxDistance
+	}
+	return distance
+}
+
+// newBKTree builds the index of the plain words among the terms. Terms marked with the nofuzzy
+// option and terms shorter than fuzzyMinTermLength are left out.
+func newBKTree(terms []term, maxDistance int) *bkTree {
+	tree := &bkTree{}
+	for _, t := range terms {
+		if t.hasOption(termOptionNoFuzzy) || isJapaneseWord(t.Word) || !isPlainWord(t.Word) {
+			continue
+		}
+		word := strings.ToLower(t.Word)
+		if utf8.RuneCountInString(word) < fuzzyMinTermLength {
+			continue
+		}
+		if distance := fuzzyMaxDistance(word, maxDistance); distance > 0 {
+			tree.add(word, distance)
+		}
+	}
+	return tree
+}
+
+// add inserts a word that may be matched at up to maxDistance edits
+func (t *bkTree) add(word string, maxDistance int) {
+	if maxDistance > t.maxDistance {
+		t.maxDistance = maxDistance
+	}
+
+	node := &bkTreeNode{word: word, maxDistance: maxDistance, children: map[int]*bkTreeNode{}}
+	if t.root == nil {
+		t.root = node
+		return
+	}
+
+	current := t.root
+	for {
+		distance := damerauLevenshtein(current.word, word)
+		if distance == 0 {
+			return
+		}
+		child, ok := current.children[distance]
+		if !ok {
+			current.children[distance] = node
+			return
+		}
+		current = child
+	}
+}
+
+// search returns the terms within their allowed distance of the word
+func (t *bkTree) search(word string) []fuzzyMatch {
+	var matches []fuzzyMatch
+	if t == nil || t.root == nil {
+		return matches
+	}
+
+	candidates := []*bkTreeNode{t.root}
+	for len(candidates) > 0 {
+		node := candidates[len(candidates)-1]
+		candidates = candidates[:len(candidates)-1]
+
+		distance := damerauLevenshtein(node.word, word)
+		if distance <= node.maxDistance {
+			matches = append(matches, fuzzyMatch{Word: node.word, Distance: distance})
+		}
+
+		// By the triangle inequality, only children at a distance within maxDistance of the
+		// word's distance can be within maxDistance of the word
+		for childDistance, child := range node.children {
+			if childDistance >= distance-t.maxDistance && childDistance <= distance+t.maxDistance {
+				candidates = append(candidates, child)
+			}
+		}
+	}
+
+	return matches
+}
+
+// damerauLevenshtein returns the number of insertions, deletions, substitutions and transpositions
+// of adjacent characters needed to turn a into b
+func damerauLevenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	maxDistance := len(ra) + len(rb)
+
+	// lastRow maps each rune to the last row of a it was seen in
+	lastRow := map[rune]int{}
+
+	// d is offset by one row and column to hold the maxDistance sentinels
+	d := make([][]int, len(ra)+2)
+	for i := range d {
+		d[i] = make([]int, len(rb)+2)
+	}
+	d[0][0] = maxDistance
+	for i := 0; i <= len(ra); i++ {
+		d[i+1][0] = maxDistance
+		d[i+1][1] = i
+	}
+	for j := 0; j <= len(rb); j++ {
+		d[0][j+1] = maxDistance
+		d[1][j+1] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		lastMatchingColumn := 0
+		for j := 1; j <= len(rb); j++ {
+			k := lastRow[rb[j-1]]
+			l := lastMatchingColumn
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+				lastMatchingColumn = j
+			}
+			d[i+1][j+1] = min(
+				d[i][j]+cost,              // substitution
+				d[i+1][j]+1,               // insertion
+				d[i][j+1]+1,               // deletion
+				d[k][l]+(i-k-1)+1+(j-l-1), // transposition
+			)
+		}
+		lastRow[ra[i-1]] = i
+	}
+
+	return d[len(ra)+1][len(rb)+1]
+}
+
+// detectFuzzyWords matches every word of the text within the allowed edit distance of a bad word,
+// e.g. "biatch" or "bastrd". The first letter must match, and short and common words are never
+// matched, to keep the number of false positives low.
+func (p *Plugin) detectFuzzyWords(text string, terms *termSet) []detection {
+	var detected []detection
+
+	tree := terms.fuzzyIndex
+	if tree == nil || tree.root == nil {
+		return detected
+	}
+
+	for _, match := range stemmedWordRegex.FindAllStringIndex(text, -1) {
+		word := strings.ToLower(removeAccents(text[match[0]:match[1]]))
+		if utf8.RuneCountInString(word) < fuzzyMinTermLength || isCommonWord(word) {
+			continue
+		}
+
+		best, bestWord := -1, ""
+		for _, m := range tree.search(word) {
+			if !sameFirstRune(m.Word, word) || !isFuzzyEdit(m.Word, word) {
+				continue
+			}
+			if best < 0 || m.Distance < best {
+				best, bestWord = m.Distance, m.Word
+			}
+		}
+		if best >= 0 {
+			d := newDetection(text, match[0], match[1])
+			d.Strategy = "fuzzy"
+			d.Distance = best
+			d.Term = bestWord
+			detected = append(detected, d)
+		}
+	}
+
+	return detected
+}
+
+// isFuzzyEdit checks if a word within the allowed distance of a term is a typo of the term. Terms
+// shorter than fuzzyAnyEditMinTermLength, which are allowed a single edit, only match words with two
+// adjacent letters swapped or a vowel added or dropped.
+func isFuzzyEdit(termWord, word string) bool {
+	a, b := []rune(termWord), []rune(word)
+	if len(a) >= fuzzyAnyEditMinTermLength {
+		return true
+	}
+
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	switch {
+	case len(a) == len(b):
+		return i+1 < len(a) && a[i] == b[i+1] && a[i+1] == b[i] && string(a[i+2:]) == string(b[i+2:])
+	case len(b) == len(a)+1:
+		return isVowel(b[i]) && string(a[i:]) == string(b[i+1:])
+	case len(a) == len(b)+1:
+		return isVowel(a[i]) && string(a[i+1:]) == string(b[i:])
+	}
+	return false
+}
+
+// isVowel checks if a letter is a Latin vowel
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiou", r)
+}
+
+// sameFirstRune checks if two words start with the same character
+func sameFirstRune(a, b string) bool {
+	ra, _ := utf8.DecodeRuneInString(a)
+	rb, _ := utf8.DecodeRuneInString(b)
+	return ra == rb
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestDamerauLevenshtein(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"fuck", "fuck", 0},
+		{"fuck", "fcuk", 1},
+		{"fuck", "fuk", 1},
+		{"bitch", "biatch", 1},
+		{"fuck", "fork", 2},
+		{"shit", "", 4},
+		{"ca", "abc", 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.a+" "+tc.b, func(t *testing.T) {
+			assert.Equal(t, tc.expected, damerauLevenshtein(tc.a, tc.b))
+			assert.Equal(t, tc.expected, damerauLevenshtein(tc.b, tc.a))
+		})
+	}
+}
+
+func TestFuzzyMatching(t *testing.T) {
+	testCases := []struct {
+		name           string
+		wordList       string
+		enabled        bool
+		input          string
+		expectedOutput string
+	}{
+		{"fuzzy matching disabled by default", "fuck", false, "fcuk", "fcuk"},
+		{"transposition", "bitch", true, "bicth please", "***** please"},
+		{"deletion", "bastard", true, "Bastrd", "******"},
+		{"insertion", "bitch", true, "biatch", "******"},
+		{"long words allow more edits", "motherfucker", true, "mutherfukker", "************"},
+		{"distance scales with length", "bitch", true, "butxh", "butxh"},
+		{"first letter must match", "bitch", true, "zitch", "zitch"},
+		{"swapped letters in a short word", "fuck", true, "fcuk", "****"},
+		{"added vowel in a short word", "shit", true, "sheit", "*****"},
+		{"misspellings of the request", "fuck,shit,bitch", true, "fcuk sheit biatch", "**** ***** ******"},
+		{"substitutions in short words are not matched", "wanker", true, "walker", "walker"},
+		{"added consonants in short words are not matched", "willy", true, "wildly", "wildly"},
+		{"words shorter than four letters are not fuzzy matched", "fuck", true, "fuk", "fuk"},
+		{"common words are not fuzzy matched", "bitch", true, "batch", "batch"},
+		{"nofuzzy opt-out", "bitch#nofuzzy", true, "bicth bitch", "bicth *****"},
+		{"regex terms are not fuzzy matched", "bit+ch", true, "bicth", "bicth"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: tc.wordList, FuzzyMatching: tc.enabled, FuzzyMaxDistance: 2})
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+
+	t.Run("common words pass with the default list", func(t *testing.T) {
+		p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: defaultBadWordsList(t), FuzzyMatching: true, FuzzyMaxDistance: 2})
+
+		for _, word := range []string{"hello", "shut", "duck", "dock", "cent", "batch", "pitch", "where", "funny",
+			"walker", "bitcoin", "sitting", "shifting", "fetching", "colon", "seen", "prone", "shiite"} {
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: word})
+			assert.Empty(t, s)
+			assert.Equal(t, word, rpost.Message)
+		}
+	})
+
+	t.Run("distance is reported", func(t *testing.T) {
+		p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: "motherfucker", FuzzyMatching: true, FuzzyMaxDistance: 2})
+
+		detected := p.detectFuzzyWords("you mutherfukker", p.getTerms())
+		require.Len(t, detected, 1)
+		assert.Equal(t, "mutherfukker", detected[0].Word)
+		assert.Equal(t, "fuzzy", detected[0].Strategy)
+		assert.Equal(t, 2, detected[0].Distance)
+	})
+}
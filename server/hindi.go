@@ -0,0 +1,305 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// hindiTerm is a Hindi bad word reduced to the canonical romanized form of each of its words
+type hindiTerm struct {
+	Word string
+	Keys []string
+}
+
+// hindiToken is a word of the scanned text together with its byte span in the original text
+type hindiToken struct {
+	Key        string
+	Start, End int
+}
+
+// devanagariConsonants maps Devanagari consonants to their romanized form without the inherent vowel
+var devanagariConsonants = map[rune]string{
+	'क': "k", 'ख': "kh", 'ग': "g", 'घ': "gh", 'ङ': "n",
+	'च': "ch", 'छ': "chh", 'ज': "j", 'झ': "jh", 'ञ': "n",
+	'ट': "t", 'ठ': "th", 'ड': "d", 'ढ': "dh", 'ण': "n",
+	'त': "t", 'थ': "th", 'द': "d", 'ध': "dh", 'न': "n",
+	'प': "p", 'फ': "ph", 'ब': "b", 'भ': "bh", 'म': "m",
+	'य': "y", 'र': "r", 'ल': "l", 'व': "v",
+	'श': "sh", 'ष': "sh", 'स': "s", 'ह': "h",
+	'\u0958': "q", '\u0959': "kh", '\u095A': "g", '\u095B': "z",
+	'\u095C': "r", '\u095D': "rh", '\u095E': "f", '\u095F': "y",
+}
+
+// devanagariNuktaConsonants maps the romanized form of a consonant to the form it takes when
+// followed by a separate nukta sign
+var devanagariNuktaConsonants = map[string]string{
+	"k": "q", "kh": "kh", "g": "g", "j": "z", "d": "r", "dh": "rh", "ph": "f",
+}
+
+// devanagariVowels maps independent Devanagari vowels to their romanized form
+var devanagariVowels = map[rune]string{
+	'अ': "a", 'आ': "aa", 'इ': "i", 'ई': "ee", 'उ': "u", 'ऊ': "oo",
+	'ऋ': "ri", 'ए': "e", 'ऐ': "ai", 'ओ': "o", 'औ': "au", 'ऑ': "o",
+}
+
+// devanagariMatras maps Devanagari dependent vowel signs to their romanized form
+var devanagariMatras = map[rune]string{
+	'ा': "aa", 'ि': "i", 'ी': "ee", 'ु': "u", 'ू': "oo",
+	'ृ': "ri", 'े': "e", 'ै': "ai", 'ो': "o", 'ौ': "au", 'ॉ': "o",
+}
+
+const (
+	devanagariVirama      = '्'
+	devanagariNukta       = '़'
+	devanagariAnusvara    = 'ं'
+	devanagariCandrabindu = 'ँ'
+	devanagariVisarga     = 'ः'
+)
+
+// hindiRomanizationRules rewrite common Latin spellings of Hindi sounds onto a single
+// canonical spelling. They are applied in order, after lowercasing and accent removal.
+var hindiRomanizationRules = strings.NewReplacer(
+	"chh", "c",
+	"ch", "c",
+	"sh", "s",
+	"ph", "f",
+	"ck", "k",
+	"ee", "i",
+	"ii", "i",
+	"oo", "u",
+	"uu", "u",
+	"ai", "e",
+	"ei", "e",
+	"au", "o",
+	"ou", "o",
+	"w", "v",
+	"z", "j",
+	"q", "k",
+)
+
+// isDevanagariRune checks if a rune belongs to the Devanagari script
+func isDevanagariRune(r rune) bool {
+	return unicode.Is(unicode.Devanagari, r)
+}
+
+// isDevanagariWord checks if a word contains Devanagari characters
+func isDevanagariWord(word string) bool {
+	for _, r := range word {
+		if isDevanagariRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHindiTerm reports whether a term is handled by the Hindi matcher, either because it is
+// written in Devanagari or because it was marked as romanized Hindi
+func isHindiTerm(t term) bool {
+	return isDevanagariWord(t.Word) || t.hasOption(termOptionHindi)
+}
+
+// separateHindiTerms splits the terms handled by the Hindi matcher from the remaining terms
+func separateHindiTerms(terms []term) (hindiTerms, otherTerms []term) {
+	for _, t := range terms {
+		if isHindiTerm(t) {
+			hindiTerms = append(hindiTerms, t)
+		} else {
+			otherTerms = append(otherTerms, t)
+		}
+	}
+	return hindiTerms, otherTerms
+}
+
+// transliterateDevanagari romanizes a single Devanagari word, applying Hindi schwa deletion
+// so that e.g. "मादरचोद" becomes "maadarchod" rather than "maadarachoda"
+func transliterateDevanagari(word string) string {
+	type syllable struct {
+		consonant string
+		vowel     string
+		inherent  bool
+	}
+
+	var syllables []syllable
+	for _, r := range word {
+		last := len(syllables) - 1
+		if consonant, ok := devanagariConsonants[r]; ok {
+			syllables = append(syllables, syllable{consonant: consonant, vowel: "a", inherent: true})
+			continue
+		}
+		if vowel, ok := devanagariVowels[r]; ok {
+			syllables = append(syllables, syllable{vowel: vowel})
+			continue
+		}
+		if last < 0 {
+			continue
+		}
+		if matra, ok := devanagariMatras[r]; ok {
+			syllables[last].vowel = matra
+			syllables[last].inherent = false
+			continue
+		}
+
+		switch r {
+		case devanagariVirama:
+			syllables[last].vowel = ""
+			syllables[last].inherent = false
+		case devanagariAnusvara, devanagariCandrabindu:
+			syllables[last].vowel += "n"
+			syllables[last].inherent = false
+		case devanagariVisarga:
+			syllables[last].vowel += "h"
+			syllables[last].inherent = false
+		case devanagariNukta:
+			if consonant, ok := devanagariNuktaConsonants[syllables[last].consonant]; ok {
+				syllables[last].consonant = consonant
+			}
+		}
+	}
+
+	// The final inherent vowel of a multi-syllable word is not pronounced.
+	if n := len(syllables); n > 1 && syllables[n-1].inherent {
+		syllables[n-1].vowel = ""
+	}
+
+	// A medial inherent vowel is not pronounced between a vowel-consonant and a
+	// consonant-vowel sequence. The rule is applied right to left.
+	for i := len(syllables) - 2; i > 0; i-- {
+		if syllables[i].inherent &&
+			syllables[i-1].vowel != "" &&
+			syllables[i+1].consonant != "" &&
+			syllables[i+1].vowel != "" {
+			syllables[i].vowel = ""
+		}
+	}
+
+	var builder strings.Builder
+	for _, s := range syllables {
+		builder.WriteString(s.consonant)
+		builder.WriteString(s.vowel)
+	}
+	return builder.String()
+}
+
+// normalizeRomanizedHindi maps a Latin spelling of a Hindi word onto its canonical form, so that
+// e.g. "chutiya", "chootiya" and "chutiyaa" all share the same key
+func normalizeRomanizedHindi(word string) string {
+	word = hindiRomanizationRules.Replace(strings.ToLower(removeAccents(word)))
+
+	var builder strings.Builder
+	var previous rune
+	for _, r := range word {
+		if r < 'a' || r > 'z' {
+			continue
+		}
+		// Drop the aspiration marker after a consonant (kh, gh, th, dh, bh, jh, ...)
+		if r == 'h' && previous != 0 && !isLatinVowel(previous) {
+			continue
+		}
+		// Collapse doubled letters (tt, aa, ...)
+		if r == previous {
+			continue
+		}
+		builder.WriteRune(r)
+		previous = r
+	}
+
+	return strings.ReplaceAll(builder.String(), "iya", "ia")
+}
+
+// isLatinVowel checks if a rune is a lowercase Latin vowel
+func isLatinVowel(r rune) bool {
+	return strings.ContainsRune("aeiou", r)
+}
+
+// hindiKey returns the canonical key of a single Devanagari or Latin word
+func hindiKey(word string) string {
+	if isDevanagariWord(word) {
+		return normalizeRomanizedHindi(transliterateDevanagari(word))
+	}
+	return normalizeRomanizedHindi(word)
+}
+
+// isHindiWordRune checks if a rune can be part of a Hindi or romanized Hindi word
+func isHindiWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)
+}
+
+// tokenizeHindi splits text into words on non-letter characters. Devanagari vowel signs are
+// combining marks, so they stay part of the word they belong to.
+func tokenizeHindi(text string) []hindiToken {
+	var tokens []hindiToken
+	start := -1
+
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if key := hindiKey(text[start:end]); key != "" {
+			tokens = append(tokens, hindiToken{Key: key, Start: start, End: end})
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		if isHindiWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// compileHindiTerms reduces each Hindi term to the canonical keys of its words
+func compileHindiTerms(terms []term) []hindiTerm {
+	var compiled []hindiTerm
+	for _, t := range terms {
+		var keys []string
+		for _, token := range tokenizeHindi(t.Word) {
+			keys = append(keys, token.Key)
+		}
+		if len(keys) > 0 {
+			compiled = append(compiled, hindiTerm{Word: t.Word, Keys: keys})
+		}
+	}
+	return compiled
+}
+
+// detectHindiWords matches Hindi terms word by word against the text, in either script
+func (p *Plugin) detectHindiWords(text string, terms *termSet) []detection {
+	var detected []detection
+
+	if len(terms.hindiTerms) == 0 {
+		return detected
+	}
+
+	tokens := tokenizeHindi(text)
+	for i := range tokens {
+		for _, t := range terms.hindiTerms {
+			if i+len(t.Keys) > len(tokens) {
+				continue
+			}
+
+			matched := true
+			for j, key := range t.Keys {
+				if tokens[i+j].Key != key {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				d := newDetection(text, tokens[i].Start, tokens[i+len(t.Keys)-1].End)
+				d.Strategy = "hindi"
+				d.Term = t.Word
+				detected = append(detected, d)
+				break
+			}
+		}
+	}
+
+	return detected
+}
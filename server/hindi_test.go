@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestHindiProfanityFilter(t *testing.T) {
+	p := Plugin{
+		configuration: &configuration{
+			CensorCharacter: "*",
+			RejectPosts:     false,
+			BadWordsList:    "चूतिया,madarchod#hindi,abc",
+			ExcludeBots:     false,
+		},
+	}
+	// Compile the regex patterns for the test
+	if err := p.compileWordRegexes(p.getConfiguration().BadWordsList); err != nil {
+		t.Fatalf("Failed to compile word regexes: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		input          string
+		expectedOutput string
+	}{
+		{"devanagari term in devanagari text", "तू चूतिया है", "तू ****** है"},
+		{"devanagari term in romanized text", "tu chutiya hai", "tu ******* hai"},
+		{"devanagari term in alternative spelling", "tu CHOOTIYAA hai", "tu ********* hai"},
+		{"romanized term in devanagari text", "वो मादरचोद है", "वो ******* है"},
+		{"romanized term in aspirated spelling", "wo maadarchodd hai", "wo *********** hai"},
+		{"ascii term still matches", "abc मादरचोद", "*** *******"},
+		{"devanagari word boundary", "चूतियापंती नहीं", "चूतियापंती नहीं"},
+		{"romanized word boundary", "chutiyapanti nahi", "chutiyapanti nahi"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+}
+
+func TestHindiKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"devanagari with final schwa deletion", "कुत्ता", "kuta"},
+		{"devanagari with medial schwa deletion", "मादरचोद", "madarcod"},
+		{"devanagari with nukta sign", "ज़लील", "jalil"},
+		{"latin long vowels", "chootiyaa", "cutia"},
+		{"latin aspirated consonants", "bhadwa", "badva"},
+		{"latin case insensitive", "KUTTA", "kuta"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hindiKey(tt.input))
+		})
+	}
+}
@@ -1,32 +1,178 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/ikawaha/kagome-dict/dict"
 	"github.com/ikawaha/kagome-dict/ipa"
 	"github.com/ikawaha/kagome/v2/tokenizer"
+	"github.com/mattermost/mattermost/server/public/model"
+	"golang.org/x/text/unicode/norm"
 )
 
+const (
+	// japaneseDictionaryIPA selects the IPA system dictionary
+	japaneseDictionaryIPA = "ipa"
+	// japaneseDictionaryUni selects the UniDic system dictionary
+	japaneseDictionaryUni = "uni"
+
+	// japaneseUniDictionaryFile is the name of the UniDic dictionary file in the assets of the plugin
+	// bundle or in the plugin's directory of the file store. UniDic is not built into the plugin, as
+	// it would double the size of the binary.
+	japaneseUniDictionaryFile = "uni.dict"
+)
+
+// japaneseTokenizeModes maps the JapaneseTokenizerMode setting to the Kagome tokenize modes
+var japaneseTokenizeModes = map[string]tokenizer.TokenizeMode{
+	"normal":   tokenizer.Normal,
+	"search":   tokenizer.Search,
+	"extended": tokenizer.Extended,
+}
+
+// japaneseSystemDictionary returns the system dictionary selected by the JapaneseDictionary setting
+func (p *Plugin) japaneseSystemDictionary(name string) (*dict.Dict, error) {
+	switch strings.ToLower(name) {
+	case "", japaneseDictionaryIPA:
+		return ipa.Dict(), nil
+	case japaneseDictionaryUni:
+		uniDict, err := p.loadJapaneseUniDictionary()
+		if err != nil {
+			p.API.LogWarn("Failed to load the UniDic dictionary, falling back to the IPA dictionary", "err", err.Error())
+			return ipa.Dict(), nil
+		}
+		return uniDict, nil
+	default:
+		return nil, fmt.Errorf("unknown Japanese dictionary %q", name)
+	}
+}
+
+// loadJapaneseUniDictionary reads the UniDic system dictionary, the uni.dict file of the
+// kagome-dict/uni module, from the assets of the plugin bundle or, if it is not bundled, from the
+// plugin's directory of the file store
+func (p *Plugin) loadJapaneseUniDictionary() (*dict.Dict, error) {
+	var data []byte
+	if bundlePath, err := p.API.GetBundlePath(); err == nil {
+		data, _ = os.ReadFile(filepath.Join(bundlePath, "assets", japaneseUniDictionaryFile))
+	}
+	if data == nil {
+		var appErr *model.AppError
+		data, appErr = p.API.ReadFile(path.Join("plugins", manifest.Id, japaneseUniDictionaryFile))
+		if appErr != nil {
+			return nil, fmt.Errorf("failed to read the UniDic dictionary %q from the plugin bundle or the file store: %w", japaneseUniDictionaryFile, appErr)
+		}
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the UniDic dictionary %q: %w", japaneseUniDictionaryFile, err)
+	}
+	uniDict, err := dict.Load(archive, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the UniDic dictionary %q: %w", japaneseUniDictionaryFile, err)
+	}
+	return uniDict, nil
+}
+
+// japaneseTokenizeMode returns the tokenize mode selected by the JapaneseTokenizerMode setting
+func japaneseTokenizeMode(name string) (tokenizer.TokenizeMode, error) {
+	if name == "" {
+		return tokenizer.Normal, nil
+	}
+	mode, ok := japaneseTokenizeModes[strings.ToLower(name)]
+	if !ok {
+		return tokenizer.Normal, fmt.Errorf("unknown Japanese tokenizer mode %q", name)
+	}
+	return mode, nil
+}
+
+// loadJapaneseUserDictionary reads the named Kagome user dictionary from the plugin's KV store or,
+// if it is not stored there, from the plugin's directory of the file store
+func (p *Plugin) loadJapaneseUserDictionary(name string) (*dict.UserDict, error) {
+	data, appErr := p.API.KVGet(name)
+	if appErr != nil {
+		return nil, fmt.Errorf("failed to read Japanese user dictionary %q from the KV store: %w", name, appErr)
+	}
+	if data == nil {
+		data, appErr = p.API.ReadFile(path.Join("plugins", manifest.Id, name))
+		if appErr != nil {
+			return nil, fmt.Errorf("failed to read Japanese user dictionary %q from the file store: %w", name, appErr)
+		}
+	}
+
+	records, err := dict.NewUserDicRecords(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Japanese user dictionary %q: %w", name, err)
+	}
+	userDict, err := records.NewUserDict()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Japanese user dictionary %q: %w", name, err)
+	}
+
+	return userDict, nil
+}
+
 func (p *Plugin) initializeJapaneseTokenizer() error {
+	t, mode, err := p.newJapaneseTokenizer(p.getConfiguration())
+	if err != nil {
+		return err
+	}
+	p.setJapaneseTokenizer(t, mode)
+	return nil
+}
+
+// newJapaneseTokenizer builds the Japanese tokenizer and selects the mode of the given
+// configuration
+func (p *Plugin) newJapaneseTokenizer(configuration *configuration) (*tokenizer.Tokenizer, tokenizer.TokenizeMode, error) {
+	systemDict, err := p.japaneseSystemDictionary(configuration.JapaneseDictionary)
+	if err != nil {
+		return nil, tokenizer.Normal, err
+	}
+	mode, err := japaneseTokenizeMode(configuration.JapaneseTokenizerMode)
+	if err != nil {
+		return nil, tokenizer.Normal, err
+	}
+
+	var options []tokenizer.Option
+	if name := strings.TrimSpace(configuration.JapaneseUserDictionary); name != "" {
+		userDict, err := p.loadJapaneseUserDictionary(name)
+		if err != nil {
+			return nil, tokenizer.Normal, err
+		}
+		options = append(options, tokenizer.UserDict(userDict))
+	}
+
 	// Initialize Japanese tokenizer
-	t, err := tokenizer.New(ipa.Dict())
+	t, err := tokenizer.New(systemDict, options...)
 	if err != nil {
-		return fmt.Errorf("failed to initialize Japanese tokenizer: %w", err)
+		return nil, tokenizer.Normal, fmt.Errorf("failed to initialize Japanese tokenizer: %w", err)
 	}
-	p.japaneseTokenizer = t
 
-	return nil
+	return t, mode, nil
+}
+
+// setJapaneseTokenizer sets the tokenizer and the mode Japanese text is analyzed with
+func (p *Plugin) setJapaneseTokenizer(t *tokenizer.Tokenizer, mode tokenizer.TokenizeMode) {
+	p.configurationLock.Lock()
+	defer p.configurationLock.Unlock()
+	p.japaneseTokenizer = t
+	p.japaneseTokenizeMode = mode
 }
 
 // isJapaneseRune checks if a rune is a Japanese character (Hiragana, Katakana, or Kanji)
 func isJapaneseRune(r rune) bool {
-	// Hiragana: U+3040-U+309F
-	// Katakana: U+30A0-U+30FF
-	// CJK Unified Ideographs (Kanji): U+4E00-U+9FFF
-	return (r >= 0x3040 && r <= 0x309F) || // Hiragana
-		(r >= 0x30A0 && r <= 0x30FF) || // Katakana
-		(r >= 0x4E00 && r <= 0x9FFF) // Kanji
+	// Han covers every CJK Unified Ideographs block (including Extensions A to H), the CJK
+	// Compatibility Ideographs and the iteration mark 々. Katakana covers the full-width and
+	// half-width forms and the Katakana Phonetic Extensions.
+	return unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han) ||
+		isVoicedSoundMark(r) ||
+		r == 'ー' || r == 'ｰ' || r == '゛' || r == '゜' || r == '・' || r == '･'
 }
 
 // isJapaneseWord checks if a word contains Japanese characters
@@ -44,97 +190,372 @@ func isJapaneseText(text string) bool {
 	return isJapaneseWord(text)
 }
 
+// japaneseToken is a morpheme of the scanned text together with its byte span in the original text
+type japaneseToken struct {
+	Surface string
+	// BaseForm is the dictionary form of the morpheme, e.g. "死ぬ" for "死ん"
+	BaseForm string
+	// POS is the top-level part of speech of the morpheme, e.g. "動詞"
+	POS string
+	// Reading is the katakana reading of the morpheme, e.g. "クソ" for "糞"
+	Reading    string
+	Start, End int
+}
+
 // tokenizeJapanese tokenizes Japanese text using Kagome morphological analyzer
-func tokenizeJapanese(text string, tokenizer *tokenizer.Tokenizer) []string {
-	tokens := tokenizer.Tokenize(text)
-	var words []string
+func tokenizeJapanese(text string, tokenizer *tokenizer.Tokenizer, mode tokenizer.TokenizeMode) []japaneseToken {
+	var tokens []japaneseToken
+	if tokenizer == nil {
+		return tokens
+	}
 
-	for _, token := range tokens {
+	for _, token := range tokenizer.Analyze(text, mode) {
 		surface := token.Surface
-		if surface != "" && surface != " " {
-			words = append(words, strings.ToLower(surface))
+		if token.Class == tokenizerDummy || surface == "" || surface == " " {
+			continue
+		}
+
+		baseForm, ok := token.BaseForm()
+		if !ok || baseForm == "" || baseForm == "*" {
+			baseForm = surface
+		}
+		var pos string
+		if features := token.POS(); len(features) > 0 {
+			pos = features[0]
+		}
+		reading, ok := token.Reading()
+		if !ok || reading == "" || reading == "*" {
+			reading = surface
+		}
+
+		tokens = append(tokens, japaneseToken{
+			Surface:  surface,
+			BaseForm: baseForm,
+			POS:      pos,
+			Reading:  reading,
+			Start:    token.Position,
+			End:      token.Position + len(surface),
+		})
+	}
+
+	return tokens
+}
+
+// tokenizerDummy is the class of the BOS/EOS tokens added by the tokenizer
+const tokenizerDummy = tokenizer.DUMMY
+
+const (
+	// japaneseMatchTokenExact only matches a term that is exactly one token
+	japaneseMatchTokenExact = "token-exact"
+	// japaneseMatchTokenSequence matches a term that starts and ends on token boundaries
+	japaneseMatchTokenSequence = "token-sequence"
+	// japaneseMatchSubstring matches a term anywhere in the text, including inside longer words
+	japaneseMatchSubstring = "substring"
+)
+
+// japaneseMatchStrictness ranks the Japanese match strategies from the strictest to the loosest
+var japaneseMatchStrictness = map[string]int{
+	japaneseMatchTokenExact:    0,
+	japaneseMatchTokenSequence: 1,
+	japaneseMatchSubstring:     2,
+}
+
+// japaneseTokenSpans indexes the tokens of a text by the byte offsets they start and end at
+type japaneseTokenSpans struct {
+	// tokenEnds maps the start of each token to its end
+	tokenEnds map[int]int
+	ends      map[int]bool
+}
+
+// newJapaneseTokenSpans indexes the spans of the given tokens
+func newJapaneseTokenSpans(tokens []japaneseToken) japaneseTokenSpans {
+	spans := japaneseTokenSpans{
+		tokenEnds: make(map[int]int, len(tokens)),
+		ends:      make(map[int]bool, len(tokens)),
+	}
+	for _, token := range tokens {
+		spans.tokenEnds[token.Start] = token.End
+		spans.ends[token.End] = true
+	}
+	return spans
+}
+
+// strategy returns the strictest strategy that matches the span [start, end) of the original text
+func (s japaneseTokenSpans) strategy(start, end int) string {
+	tokenEnd, startsToken := s.tokenEnds[start]
+	switch {
+	case startsToken && tokenEnd == end:
+		return japaneseMatchTokenExact
+	case startsToken && s.ends[end]:
+		return japaneseMatchTokenSequence
+	default:
+		return japaneseMatchSubstring
+	}
+}
+
+// japaneseTermStrictness returns the loosest strategy a Japanese term may match with. The
+// compound option allows substring matches, the exact and sequence options restrict matching
+// to a single token or a token sequence, and other terms use the configured default.
+func japaneseTermStrictness(t term, defaultStrictness string) string {
+	switch {
+	case t.hasOption(termOptionCompound):
+		return japaneseMatchSubstring
+	case t.hasOption(termOptionExact):
+		return japaneseMatchTokenExact
+	case t.hasOption(termOptionSequence):
+		return japaneseMatchTokenSequence
+	}
+	if _, ok := japaneseMatchStrictness[defaultStrictness]; ok {
+		return defaultStrictness
+	}
+	return japaneseMatchTokenSequence
+}
+
+// normalizeJapanese folds the spelling differences that do not change how a Japanese word reads:
+// full-width and half-width forms are unified (NFKC), katakana is folded to hiragana and prolonged
+// sound marks following kana are dropped. "バカ", "ﾊﾞｶ" and "ばーか" all normalize to "ばか".
+func normalizeJapanese(text string) normalizedText {
+	var builder normalizedTextBuilder
+	previousKana := false
+
+	for start := 0; start < len(text); {
+		r, size := utf8.DecodeRuneInString(text[start:])
+		end := start + size
+
+		// Keep a kana together with its (half-width or combining) voiced sound mark so that
+		// NFKC can compose them into a single character
+		for end < len(text) {
+			next, nextSize := utf8.DecodeRuneInString(text[end:])
+			if !isVoicedSoundMark(next) {
+				break
+			}
+			end += nextSize
+		}
+
+		if previousKana && isProlongedSoundMark(r) {
+			builder.add("", start, end)
+			start = end
+			continue
 		}
+
+		normalized := foldKatakana(strings.ToLower(norm.NFKC.String(text[start:end])))
+		builder.add(normalized, start, end)
+
+		lastRune, _ := utf8.DecodeLastRuneInString(normalized)
+		previousKana = unicode.Is(unicode.Hiragana, lastRune)
+		start = end
 	}
 
-	return words
+	return builder.build()
 }
 
-// detectJapaneseWords uses tokenization for Japanese words to ensure proper word boundaries
-func (p *Plugin) detectJapaneseWords(text string, japaneseWords []string) []string {
-	var detected []string
+// isVoicedSoundMark checks if a rune is a half-width or combining (semi-)voiced sound mark
+func isVoicedSoundMark(r rune) bool {
+	return r == '\uFF9E' || r == '\uFF9F' || r == '\u3099' || r == '\u309A'
+}
+
+// isProlongedSoundMark checks if a rune is a prolonged sound mark or one of the wave dashes used in its place
+func isProlongedSoundMark(r rune) bool {
+	return r == 'ー' || r == 'ｰ' || r == '〜' || r == '～'
+}
+
+// foldKatakana converts full-width katakana to the equivalent hiragana
+func foldKatakana(text string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'ァ' && r <= 'ヶ') || r == 'ヽ' || r == 'ヾ' {
+			return r - ('ァ' - 'ぁ')
+		}
+		return r
+	}, text)
+}
+
+// findNormalizedOccurrences finds every occurrence of a normalized word in a normalized text and
+// returns them as detections in the original text
+func findNormalizedOccurrences(text string, normalized normalizedText, word string) []detection {
+	var detected []detection
+	for offset := 0; offset < len(normalized.text); {
+		index := strings.Index(normalized.text[offset:], word)
+		if index < 0 {
+			break
+		}
+		offset += index + len(word)
+		start, end := normalized.originalSpan(offset-len(word), offset)
+		detected = append(detected, newDetection(text, start, end))
+	}
+	return detected
+}
+
+// detectJapaneseWords uses tokenization for Japanese words to ensure proper word boundaries. Each
+// occurrence of a term is classified by the strictest strategy that matches it, and is only
+// detected if the term's strictness allows that strategy.
+func (p *Plugin) detectJapaneseWords(text string, japaneseTerms []term) []detection {
+	var detected []detection
+
+	normalized := normalizeJapanese(text)
 
 	// Only tokenize if text contains Japanese characters
-	if !isJapaneseText(text) {
+	if !isJapaneseText(normalized.text) {
 		return detected
 	}
 
 	// Tokenize the Japanese text
-	tokens := tokenizeJapanese(text, p.getJapaneseTokenizer())
+	spans := newJapaneseTokenSpans(tokenizeJapanese(text, p.getJapaneseTokenizer(), p.getJapaneseTokenizeMode()))
+	defaultStrictness := p.getConfiguration().JapaneseMatchStrictness
 
 	// Check each Japanese bad word against the tokenized text
-	for _, badWord := range japaneseWords {
-		if badWord == "" {
+	for _, badWord := range japaneseTerms {
+		badWordNormalized := normalizeJapanese(strings.TrimSpace(badWord.Word)).text
+		if badWordNormalized == "" {
 			continue
 		}
 
-		badWordLower := strings.ToLower(strings.TrimSpace(badWord))
-
-		// First try exact token matching (for proper morphological words)
-		tokenMatched := false
-		for _, token := range tokens {
-			if token == badWordLower {
-				detected = append(detected, badWord)
-				tokenMatched = true
-				break
+		strictness := japaneseMatchStrictness[japaneseTermStrictness(badWord, defaultStrictness)]
+		for _, occurrence := range findNormalizedOccurrences(text, normalized, badWordNormalized) {
+			occurrence.Strategy = spans.strategy(occurrence.Start, occurrence.End)
+			if japaneseMatchStrictness[occurrence.Strategy] <= strictness {
+				detected = append(detected, occurrence)
 			}
 		}
-
-		// If no token match, fall back to substring matching for compound words
-		// This handles cases where compounds like "クソ野郎" might be tokenized as separate parts
-		if !tokenMatched && strings.Contains(strings.ToLower(text), badWordLower) {
-			detected = append(detected, badWord)
-		}
 	}
 
 	return detected
 }
 
 // detectJapaneseWordsWithTokenization uses tokenization + regex approach for Japanese text
-func (p *Plugin) detectJapaneseWordsWithTokenization(text string, japaneseWords []string) []string {
-	var detected []string
+func (p *Plugin) detectJapaneseWordsWithTokenization(text string, japaneseTerms []term, terms *termSet) []detection {
+	var detected []detection
+
+	normalized := normalizeJapanese(text)
 
 	// Only process if text contains Japanese characters
-	if !isJapaneseText(text) {
+	if !isJapaneseText(normalized.text) {
 		return detected
 	}
 
-	// Get the pre-compiled regex
-	regex := p.getJapaneseWordsRegex()
-	if regex == nil {
-		return p.detectJapaneseWords(text, japaneseWords)
+	// Use the pre-compiled regex to skip tokenization when no word occurs in the text at all
+	if regex := terms.japaneseWordsRegex; regex != nil && !regex.MatchString(normalized.text) {
+		return detected
 	}
 
-	// Tokenize the Japanese text to create word boundaries
-	tokens := tokenizeJapanese(text, p.getJapaneseTokenizer())
-	tokenizedText := strings.Join(tokens, " ") // Create spaces between tokens
+	return p.detectJapaneseWords(text, japaneseTerms)
+}
 
-	// Find matches in tokenized text
-	matches := regex.FindAllString(strings.ToLower(tokenizedText), -1)
+// japaneseTermsOf returns the Japanese terms among the given terms
+func japaneseTermsOf(terms []term) []term {
+	var japaneseTerms []term
+	for _, t := range terms {
+		if isJapaneseWord(t.Word) {
+			japaneseTerms = append(japaneseTerms, t)
+		}
+	}
+	return japaneseTerms
+}
 
-	// Return the original words that match
-	for _, match := range matches {
-		for _, word := range japaneseWords {
-			word = strings.TrimSpace(word)
-			if word != "" && strings.ToLower(word) == match {
-				detected = append(detected, word)
-				break
+// japaneseLemmaTerms returns the Japanese terms marked for lemma matching
+func japaneseLemmaTerms(terms []term) []term {
+	var lemmaTerms []term
+	for _, t := range terms {
+		if t.hasOption(termOptionLemma) && isJapaneseWord(t.Word) {
+			lemmaTerms = append(lemmaTerms, t)
+		}
+	}
+	return lemmaTerms
+}
+
+// sameJapaneseLemma checks if two morphemes share the same dictionary form and part of speech
+func sameJapaneseLemma(a, b japaneseToken) bool {
+	return a.POS == b.POS && normalizeJapanese(a.BaseForm).text == normalizeJapanese(b.BaseForm).text
+}
+
+// detectJapaneseLemmas matches terms against the dictionary form of each morpheme, so that a verb
+// or adjective term also matches its conjugated forms ("死ぬ" matches "死んで" and "死ねよ")
+func (p *Plugin) detectJapaneseLemmas(text string, lemmaTerms []term) []detection {
+	var detected []detection
+
+	// Only tokenize if text contains Japanese characters
+	if !isJapaneseText(normalizeJapanese(text).text) {
+		return detected
+	}
+
+	japaneseTokenizer, mode := p.getJapaneseTokenizer(), p.getJapaneseTokenizeMode()
+	tokens := tokenizeJapanese(text, japaneseTokenizer, mode)
+
+	for _, t := range lemmaTerms {
+		termTokens := tokenizeJapanese(t.Word, japaneseTokenizer, mode)
+		if len(termTokens) == 0 {
+			continue
+		}
+
+		for i := 0; i+len(termTokens) <= len(tokens); i++ {
+			matched := true
+			for j, termToken := range termTokens {
+				if !sameJapaneseLemma(tokens[i+j], termToken) {
+					matched = false
+					break
+				}
 			}
+			if matched {
+				d := newDetection(text, tokens[i].Start, tokens[i+len(termTokens)-1].End)
+				d.Strategy = "lemma"
+				d.Term = t.Word
+				detected = append(detected, d)
+			}
+		}
+	}
+
+	return detected
+}
+
+// japaneseReadingTerms returns the Japanese terms marked for reading or romaji matching
+func japaneseReadingTerms(terms []term) []term {
+	var readingTerms []term
+	for _, t := range terms {
+		if (t.hasOption(termOptionReading) || t.hasOption(termOptionRomaji)) && isJapaneseWord(t.Word) {
+			readingTerms = append(readingTerms, t)
 		}
 	}
+	return readingTerms
+}
+
+// japaneseReading returns the hiragana reading of a word, e.g. "くそ" for "糞"
+func japaneseReading(word string, japaneseTokenizer *tokenizer.Tokenizer, mode tokenizer.TokenizeMode) string {
+	var builder strings.Builder
+	for _, token := range tokenizeJapanese(word, japaneseTokenizer, mode) {
+		builder.WriteString(token.Reading)
+	}
+	return normalizeJapanese(builder.String()).text
+}
+
+// detectJapaneseReadings matches terms by their reading, written in kana ("くそ" for "糞") when
+// marked with the reading option and in Hepburn romaji ("kuso") when marked with the romaji option
+func (p *Plugin) detectJapaneseReadings(text string, readingTerms []term) []detection {
+	var detected []detection
 
-	// If no matches found with tokenization, fall back to the old approach
-	if len(detected) == 0 {
-		return p.detectJapaneseWords(text, japaneseWords)
+	japaneseTokenizer, mode := p.getJapaneseTokenizer(), p.getJapaneseTokenizeMode()
+
+	var kanaReadings []term
+	var romajiReadings []string
+	for _, t := range readingTerms {
+		reading := japaneseReading(t.Word, japaneseTokenizer, mode)
+		if reading == "" {
+			continue
+		}
+		if t.hasOption(termOptionReading) {
+			kanaReadings = append(kanaReadings, term{Word: reading, Options: t.Options})
+		}
+		if romaji := canonicalRomaji(hiraganaToRomaji(reading)); romaji != "" && t.hasOption(termOptionRomaji) {
+			romajiReadings = append(romajiReadings, romaji)
+		}
+	}
+
+	if len(kanaReadings) > 0 {
+		for _, d := range p.detectJapaneseWords(text, kanaReadings) {
+			d.Strategy = "reading " + d.Strategy
+			detected = append(detected, d)
+		}
+	}
+	if len(romajiReadings) > 0 {
+		detected = append(detected, detectRomajiWords(text, romajiReadings)...)
 	}
 
 	return detected
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ikawaha/kagome-dict/ipa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+)
+
+const testUserDictionary = "# custom compounds\nクソ野郎,クソ野郎,クソヤロウ,カスタム名詞\n"
+
+func TestJapaneseDictionarySettings(t *testing.T) {
+	t.Run("unknown dictionary is rejected", func(t *testing.T) {
+		p := Plugin{}
+		_, err := p.japaneseSystemDictionary("jumandic")
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown tokenizer mode is rejected", func(t *testing.T) {
+		_, err := japaneseTokenizeMode("fast")
+		assert.Error(t, err)
+	})
+
+	for _, mode := range []string{"", "normal", "search", "extended"} {
+		t.Run("tokenizer mode "+mode, func(t *testing.T) {
+			p := Plugin{
+				configuration: &configuration{
+					CensorCharacter:       "*",
+					BadWordsList:          "バカ",
+					JapaneseTokenizerMode: mode,
+				},
+			}
+			require.NoError(t, p.compileWordRegexes(p.getConfiguration().BadWordsList))
+			require.NoError(t, p.initializeJapaneseTokenizer())
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: "あなたはバカです。"})
+			assert.Empty(t, s)
+			assert.Equal(t, "あなたは**です。", rpost.Message)
+		})
+	}
+
+	// The IPA dictionary stands in for UniDic, which is not built into the plugin
+	var dictionary bytes.Buffer
+	writer := zip.NewWriter(&dictionary)
+	require.NoError(t, ipa.Dict().Save(writer))
+	require.NoError(t, writer.Close())
+
+	newUniPlugin := func(t *testing.T) (*Plugin, *plugintest.API) {
+		p := createMockPlugin(t, &configuration{
+			CensorCharacter:    "*",
+			BadWordsList:       "死ぬ#lemma",
+			JapaneseDictionary: "uni",
+		})
+		return p, p.API.(*plugintest.API)
+	}
+
+	t.Run("UniDic dictionary from the plugin bundle", func(t *testing.T) {
+		bundlePath := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(bundlePath, "assets"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(bundlePath, "assets", "uni.dict"), dictionary.Bytes(), 0o600))
+
+		p, api := newUniPlugin(t)
+		api.On("GetBundlePath").Return(bundlePath, nil)
+		require.NoError(t, p.OnConfigurationChange())
+		api.AssertNotCalled(t, "ReadFile", mock.Anything)
+
+		rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: "死んでしまえ"})
+		assert.Empty(t, s)
+		assert.Equal(t, "**でしまえ", rpost.Message)
+	})
+
+	t.Run("UniDic dictionary from the file store", func(t *testing.T) {
+		p, api := newUniPlugin(t)
+		api.On("GetBundlePath").Return(t.TempDir(), nil)
+		api.On("ReadFile", "plugins/"+manifest.Id+"/uni.dict").Return(dictionary.Bytes(), nil)
+		require.NoError(t, p.OnConfigurationChange())
+
+		rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: "死んでしまえ"})
+		assert.Empty(t, s)
+		assert.Equal(t, "**でしまえ", rpost.Message)
+	})
+
+	t.Run("missing UniDic dictionary falls back to IPA", func(t *testing.T) {
+		p, api := newUniPlugin(t)
+		api.On("GetBundlePath").Return(t.TempDir(), nil)
+		api.On("ReadFile", "plugins/"+manifest.Id+"/uni.dict").Return(nil, model.NewAppError("ReadFile", "not_found", nil, "", 404))
+		api.On("LogWarn", "Failed to load the UniDic dictionary, falling back to the IPA dictionary", "err", mock.Anything).Once()
+		require.NoError(t, p.OnConfigurationChange())
+		api.AssertExpectations(t)
+
+		rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: "死んでしまえ"})
+		assert.Empty(t, s)
+		assert.Equal(t, "**でしまえ", rpost.Message)
+	})
+
+	t.Run("an invalid configuration leaves the previous one in place", func(t *testing.T) {
+		p, api := newUniPlugin(t)
+		api.On("GetBundlePath").Return(t.TempDir(), nil)
+		api.On("ReadFile", "plugins/"+manifest.Id+"/uni.dict").Return(dictionary.Bytes(), nil)
+		require.NoError(t, p.OnConfigurationChange())
+		previous := p.getConfiguration()
+
+		invalid := &configuration{CensorCharacter: "#", BadWordsList: "fuck", JapaneseTokenizerMode: "fast"}
+		api.ExpectedCalls = slices.DeleteFunc(api.ExpectedCalls, func(call *mock.Call) bool { return call.Method == "LoadPluginConfiguration" })
+		api.On("LoadPluginConfiguration", mock.AnythingOfType("*main.configuration")).Return(nil).Run(func(args mock.Arguments) {
+			*args.Get(0).(*configuration) = *invalid
+		})
+		assert.Error(t, p.OnConfigurationChange())
+		assert.Same(t, previous, p.getConfiguration())
+
+		invalid.JapaneseTokenizerMode, invalid.BadWordsList = "", "fuck("
+		assert.Error(t, p.OnConfigurationChange())
+		assert.Same(t, previous, p.getConfiguration())
+
+		rpost, _ := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: "死んでしまえ fuck"})
+		assert.Equal(t, "**でしまえ fuck", rpost.Message)
+	})
+}
+
+func TestJapaneseUserDictionary(t *testing.T) {
+	t.Run("user dictionary from the KV store", func(t *testing.T) {
+		p := createMockPlugin(t, &configuration{
+			CensorCharacter:        "*",
+			BadWordsList:           "クソ",
+			JapaneseUserDictionary: "slang.csv",
+		})
+		p.API.(*plugintest.API).On("KVGet", "slang.csv").Return([]byte(testUserDictionary), nil)
+		require.NoError(t, p.OnConfigurationChange())
+
+		tokens := tokenizeJapanese("このクソ野郎が", p.getJapaneseTokenizer(), p.getJapaneseTokenizeMode())
+		assert.Contains(t, tokenSurfaces(tokens), "クソ野郎")
+	})
+
+	t.Run("user dictionary from the file store", func(t *testing.T) {
+		p := createMockPlugin(t, &configuration{
+			CensorCharacter:        "*",
+			BadWordsList:           "クソ",
+			JapaneseUserDictionary: "slang.csv",
+		})
+		api := p.API.(*plugintest.API)
+		api.On("KVGet", "slang.csv").Return(nil, nil)
+		api.On("ReadFile", "plugins/mattermost-profanity-filter/slang.csv").Return([]byte(testUserDictionary), nil)
+		require.NoError(t, p.OnConfigurationChange())
+
+		tokens := tokenizeJapanese("このクソ野郎が", p.getJapaneseTokenizer(), p.getJapaneseTokenizeMode())
+		assert.Contains(t, tokenSurfaces(tokens), "クソ野郎")
+	})
+
+	t.Run("missing user dictionary is reported", func(t *testing.T) {
+		p := createMockPlugin(t, &configuration{
+			CensorCharacter:        "*",
+			BadWordsList:           "クソ",
+			JapaneseUserDictionary: "missing.csv",
+		})
+		api := p.API.(*plugintest.API)
+		api.On("KVGet", "missing.csv").Return(nil, nil)
+		api.On("ReadFile", "plugins/mattermost-profanity-filter/missing.csv").Return(nil, model.NewAppError("ReadFile", "not_found", nil, "", 404))
+		assert.Error(t, p.OnConfigurationChange())
+	})
+}
+
+// tokenSurfaces returns the surface of each token
+func tokenSurfaces(tokens []japaneseToken) []string {
+	surfaces := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		surfaces = append(surfaces, token.Surface)
+	}
+	return surfaces
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestJapaneseLemmaMatching(t *testing.T) {
+	p := Plugin{
+		configuration: &configuration{
+			CensorCharacter: "*",
+			RejectPosts:     false,
+			BadWordsList:    "死ぬ#lemma,殺す",
+			ExcludeBots:     false,
+		},
+	}
+	// Compile the regex patterns for the test
+	if err := p.compileWordRegexes(p.getConfiguration().BadWordsList); err != nil {
+		t.Fatalf("Failed to compile word regexes: %v", err)
+	}
+
+	// Initialize Japanese tokenizer
+	if err := p.initializeJapaneseTokenizer(); err != nil {
+		t.Fatalf("Failed to initialize Japanese tokenizer: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		input          string
+		expectedOutput string
+	}{
+		{"lemma term matches dictionary form", "もう死ぬ", "もう**"},
+		{"lemma term matches imperative with particle", "お前なんか死ねよ", "お前なんか**よ"},
+		{"lemma term matches te-form", "お前なんか死んでしまえ", "お前なんか**でしまえ"},
+		{"lemma term matches past tense", "死んだ", "**だ"},
+		{"surface term matches dictionary form", "殺す", "**"},
+		{"surface term does not match conjugation", "殺してやる", "殺してやる"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+}
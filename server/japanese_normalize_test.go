@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestJapaneseKanaNormalization(t *testing.T) {
+	p := Plugin{
+		configuration: &configuration{
+			CensorCharacter: "*",
+			RejectPosts:     false,
+			BadWordsList:    "バカ,くそ野郎",
+			ExcludeBots:     false,
+		},
+	}
+	// Compile the regex patterns for the test
+	if err := p.compileWordRegexes(p.getConfiguration().BadWordsList); err != nil {
+		t.Fatalf("Failed to compile word regexes: %v", err)
+	}
+
+	// Initialize Japanese tokenizer
+	if err := p.initializeJapaneseTokenizer(); err != nil {
+		t.Fatalf("Failed to initialize Japanese tokenizer: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		input          string
+		expectedOutput string
+	}{
+		{"katakana term matches katakana", "あなたはバカです。", "あなたは**です。"},
+		{"katakana term matches hiragana", "あなたはばかです。", "あなたは**です。"},
+		{"katakana term matches half-width katakana", "あなたはﾊﾞｶです。", "あなたは***です。"},
+		{"katakana term matches prolonged sound mark", "あなたはバーカです。", "あなたは***です。"},
+		{"trailing wave dash is kept", "ばか〜", "**〜"},
+		{"hiragana term matches katakana", "このクソ野郎が！", "この****が！"},
+		{"half-width and full-width mixed", "このｸｿ野郎が！", "この****が！"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+}
+
+func TestNormalizeJapanese(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"katakana to hiragana", "バカ", "ばか"},
+		{"half-width katakana with voiced mark", "ﾊﾞｶ", "ばか"},
+		{"half-width katakana with semi-voiced mark", "ﾊﾟﾝ", "ぱん"},
+		{"prolonged sound mark after kana dropped", "バーカ", "ばか"},
+		{"prolonged sound mark variants dropped", "ばかｰ〜～", "ばか"},
+		{"full-width latin to ascii", "ＭｙＳＱＬ", "mysql"},
+		{"kanji unchanged", "馬鹿", "馬鹿"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizeJapanese(tt.input).text)
+		})
+	}
+
+	t.Run("offsets map to the original text", func(t *testing.T) {
+		text := "あﾊﾞｶ"
+		normalized := normalizeJapanese(text)
+		start, end := normalized.originalSpan(len("あ"), len(normalized.text))
+		assert.Equal(t, "ﾊﾞｶ", text[start:end])
+	})
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestJapaneseReadingMatching(t *testing.T) {
+	p := Plugin{
+		configuration: &configuration{
+			CensorCharacter: "*",
+			RejectPosts:     false,
+			BadWordsList:    "糞#reading#romaji#compound,馬鹿#romaji,畜生#romaji",
+			ExcludeBots:     false,
+		},
+	}
+	// Compile the regex patterns for the test
+	if err := p.compileWordRegexes(p.getConfiguration().BadWordsList); err != nil {
+		t.Fatalf("Failed to compile word regexes: %v", err)
+	}
+
+	// Initialize Japanese tokenizer
+	if err := p.initializeJapaneseTokenizer(); err != nil {
+		t.Fatalf("Failed to initialize Japanese tokenizer: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		input          string
+		expectedOutput string
+	}{
+		{"kanji form matches", "これは糞だ", "これは*だ"},
+		{"kana reading matches", "これはくそだ", "これは**だ"},
+		{"katakana reading matches", "これはクソだ", "これは**だ"},
+		{"romaji reading matches", "this is kuso", "this is ****"},
+		{"romaji reading matches case insensitively", "BAKA!", "****!"},
+		{"romaji long vowel spellings match", "chikushou chikusho chikushō", "********* ******** ********"},
+		{"romaji respects word boundaries", "bakari", "bakari"},
+		{"kana reading is not matched without the reading option", "ばかだ", "ばかだ"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+}
+
+func TestHiraganaToRomaji(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ばか", "baka"},
+		{"くそ", "kuso"},
+		{"ちくしょう", "chikushou"},
+		{"きゃく", "kyaku"},
+		{"しゃしん", "shashin"},
+		{"じゃま", "jama"},
+		{"ざっこ", "zakko"},
+		{"まっちゃ", "matcha"},
+		{"ふぁん", "fan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hiraganaToRomaji(tt.input))
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestJapaneseMatchStrictness(t *testing.T) {
+	newPlugin := func(t *testing.T, wordList, strictness string) *Plugin {
+		p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: wordList, JapaneseMatchStrictness: strictness})
+		require.NoError(t, p.initializeJapaneseTokenizer())
+		return p
+	}
+
+	testCases := []struct {
+		name           string
+		wordList       string
+		strictness     string
+		input          string
+		expectedOutput string
+	}{
+		{"default does not match inside a longer word", "ばか", "", "そればかりだ", "そればかりだ"},
+		{"default matches a single token", "ばか", "", "あなたはばかです。", "あなたは**です。"},
+		{"default matches a token sequence", "クソ野郎", "", "このクソ野郎が！", "この****が！"},
+		{"global substring matches inside a longer word", "ばか", "substring", "そればかりだ", "それ**りだ"},
+		{"global token-exact does not match a token sequence", "クソ野郎", "token-exact", "このクソ野郎が！", "このクソ野郎が！"},
+		{"compound term matches inside a longer word", "ばか#compound", "", "そればかりだ", "それ**りだ"},
+		{"exact term does not match a token sequence", "クソ野郎#exact", "substring", "このクソ野郎が！", "このクソ野郎が！"},
+		{"sequence term overrides the global setting", "ばか#sequence", "substring", "そればかりだ", "そればかりだ"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newPlugin(t, tc.wordList, tc.strictness)
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+
+	t.Run("detections report the matching strategy", func(t *testing.T) {
+		p := newPlugin(t, "ばか,クソ野郎,野郎#compound", "")
+
+		detections := p.detectAllProfanityWords("ばか、このクソ野郎！野郎ども", p.getTerms())
+
+		strategies := map[string]string{}
+		for _, d := range detections {
+			strategies[d.Word] = d.Strategy
+		}
+		assert.Equal(t, map[string]string{
+			"ばか":   japaneseMatchTokenExact,
+			"クソ野郎": japaneseMatchTokenSequence,
+			"野郎":   japaneseMatchTokenExact,
+		}, strategies)
+	})
+}
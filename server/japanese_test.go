@@ -165,3 +165,77 @@ func TestJapaneseProfanityFilter(t *testing.T) {
 		assert.Equal(t, expected, rpost.Message, "Japanese word 'ばか' and English word 'MySQL' should be replaced with asterisks")
 	})
 }
+
+func TestIsJapaneseRune(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    rune
+		expected bool
+	}{
+		{"hiragana", 'ば', true},
+		{"katakana", 'バ', true},
+		{"katakana phonetic extensions", 'ㇰ', true},
+		{"half-width katakana", 'ｶ', true},
+		{"half-width voiced sound mark", 'ﾞ', true},
+		{"prolonged sound mark", 'ー', true},
+		{"half-width prolonged sound mark", 'ｰ', true},
+		{"iteration mark", '々', true},
+		{"CJK unified ideograph", '馬', true},
+		{"CJK extension A", '㐂', true},
+		{"CJK extension B", '𠮟', true},
+		{"CJK compatibility ideograph", '﨑', true},
+		{"ascii letter", 'a', false},
+		{"accented latin letter", 'é', false},
+		{"hangul", '한', false},
+		{"devanagari", 'क', false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isJapaneseRune(tt.input))
+		})
+	}
+}
+
+func TestExtendedJapaneseScriptTerms(t *testing.T) {
+	p := Plugin{
+		configuration: &configuration{
+			CensorCharacter: "*",
+			RejectPosts:     false,
+			BadWordsList:    "ｸｿ,𠮟り野郎,人々",
+			ExcludeBots:     false,
+		},
+	}
+	// Compile the regex patterns for the test
+	if err := p.compileWordRegexes(p.getConfiguration().BadWordsList); err != nil {
+		t.Fatalf("Failed to compile word regexes: %v", err)
+	}
+
+	// Initialize Japanese tokenizer
+	if err := p.initializeJapaneseTokenizer(); err != nil {
+		t.Fatalf("Failed to initialize Japanese tokenizer: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		input          string
+		expectedOutput string
+	}{
+		{"half-width katakana term", "このクソが", "この**が"},
+		{"CJK extension B term", "この𠮟り野郎が", "この****が"},
+		{"iteration mark term", "あの人々は", "あの**は"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+}
@@ -20,7 +20,7 @@ const manifestStr = `
   "support_url": "https://github.com/mattermost/mattermost-plugin-profanity-filter/issues",
   "release_notes_url": "https://github.com/mattermost/mattermost-plugin-profanity-filter/releases/tag/v1.0.0",
   "version": "1.0.0",
-  "min_server_version": "5.2.0",
+  "min_server_version": "7.1.0",
   "server": {
     "executables": {
       "darwin-amd64": "server/dist/plugin-darwin-amd64",
@@ -44,6 +44,42 @@ const manifestStr = `
         "default": null,
         "hosting": ""
       },
+      {
+        "key": "ExcludeMentions",
+        "display_name": "Exclude Mentions:",
+        "type": "bool",
+        "help_text": "When true, @mentions of existing users are never censored, so that mentions and notifications keep working.",
+        "placeholder": "",
+        "default": true,
+        "hosting": ""
+      },
+      {
+        "key": "ExcludeChannelLinks",
+        "display_name": "Exclude Channel Links:",
+        "type": "bool",
+        "help_text": "When true, ~channel links to existing channels of the team are never censored.",
+        "placeholder": "",
+        "default": true,
+        "hosting": ""
+      },
+      {
+        "key": "ExcludeEmojiShortcodes",
+        "display_name": "Exclude Emoji Shortcodes:",
+        "type": "bool",
+        "help_text": "When true, the shortcodes of existing system and custom emoji are not matched against the bad words. Emoji listed in the bad words list are still matched.",
+        "placeholder": "",
+        "default": true,
+        "hosting": ""
+      },
+      {
+        "key": "ExcludeHashtags",
+        "display_name": "Exclude Hashtags:",
+        "type": "bool",
+        "help_text": "When true, hashtags of existing usernames and channel names are never censored.",
+        "placeholder": "",
+        "default": true,
+        "hosting": ""
+      },
       {
         "key": "RejectPosts",
         "display_name": "Reject Posts:",
@@ -71,14 +107,427 @@ const manifestStr = `
         "default": "\\*",
         "hosting": ""
       },
+      {
+        "key": "CensorStyle",
+        "display_name": "Censor Style:",
+        "type": "dropdown",
+        "help_text": "How censored words are displayed. **Full Mask** replaces every letter with the censor character, **First Letter** and **First and Last Letters** keep those letters, **Fixed Length** hides the length of the word, **Grawlix** uses random symbols, **Strikethrough** strikes the word through and **Placeholder** replaces it with the placeholder.",
+        "placeholder": "",
+        "default": "mask",
+        "options": [
+          {
+            "display_name": "Full Mask (****)",
+            "value": "mask"
+          },
+          {
+            "display_name": "First Letter (f***)",
+            "value": "first-letter"
+          },
+          {
+            "display_name": "First and Last Letters (f**k)",
+            "value": "first-last-letter"
+          },
+          {
+            "display_name": "Fixed Length",
+            "value": "fixed"
+          },
+          {
+            "display_name": "Grawlix (@#$%)",
+            "value": "grawlix"
+          },
+          {
+            "display_name": "Strikethrough",
+            "value": "strikethrough"
+          },
+          {
+            "display_name": "Placeholder",
+            "value": "placeholder"
+          }
+        ],
+        "hosting": ""
+      },
+      {
+        "key": "CensorPlaceholder",
+        "display_name": "Censor Placeholder:",
+        "type": "text",
+        "help_text": "The text that replaces censored words in the **Placeholder** style.",
+        "placeholder": "E.g., [censored]",
+        "default": "[censored]",
+        "hosting": ""
+      },
+      {
+        "key": "CensorCategoryStyles",
+        "display_name": "Censor Styles by Category:",
+        "type": "text",
+        "help_text": "The censor style of each category of terms, separated by commas, overriding the **Censor Style**. Terms are assigned to a category with the ` + "`" + `category` + "`" + ` option, e.g. ` + "`" + `slur#category=slurs` + "`" + `. Styles are ` + "`" + `mask` + "`" + `, ` + "`" + `first-letter` + "`" + `, ` + "`" + `first-last-letter` + "`" + `, ` + "`" + `fixed` + "`" + `, ` + "`" + `grawlix` + "`" + `, ` + "`" + `strikethrough` + "`" + ` and ` + "`" + `placeholder` + "`" + `.",
+        "placeholder": "E.g., slurs=placeholder, mild=first-letter",
+        "default": "",
+        "hosting": ""
+      },
+      {
+        "key": "CensorEscapeMarkdown",
+        "display_name": "Escape Markdown in Censored Words:",
+        "type": "bool",
+        "help_text": "When true, markdown characters of censored words such as ` + "`" + `*` + "`" + ` are escaped, so that they are displayed as they are instead of formatting the message. Characters already escaped in the **Censor Character** are kept, and nothing is escaped in code and URLs.",
+        "placeholder": "",
+        "default": true,
+        "hosting": ""
+      },
       {
         "key": "BadWordsList",
         "display_name": "Bad Words List:",
         "type": "longtext",
-        "help_text": "The words to censor, separated by commas. Capitalization and punctuation insensitive. [Regular expressions](https://en.wikipedia.org/wiki/Regular_expression) are interpreted: If you want to censor characters as ` + "`" + `.` + "`" + `, ` + "`" + `?` + "`" + `, ` + "`" + `*` + "`" + `, ` + "`" + `{` + "`" + `, ` + "`" + `}` + "`" + `, ` + "`" + `[` + "`" + `, ` + "`" + `]` + "`" + `, please double-escape them like ` + "`" + `\\\\.` + "`" + `",
+        "help_text": "The words to censor, separated by commas. Capitalization and punctuation insensitive. [Regular expressions](https://en.wikipedia.org/wiki/Regular_expression) are interpreted: If you want to censor characters as ` + "`" + `.` + "`" + `, ` + "`" + `?` + "`" + `, ` + "`" + `*` + "`" + `, ` + "`" + `{` + "`" + `, ` + "`" + `}` + "`" + `, ` + "`" + `[` + "`" + `, ` + "`" + `]` + "`" + `, please double-escape them like ` + "`" + `\\\\.` + "`" + `. Per-term options can be appended after a ` + "`" + `#` + "`" + `, e.g. ` + "`" + `chutiya#hindi` + "`" + ` or ` + "`" + `fuck#replace=fudge` + "`" + `. Words written in Devanagari, or marked with ` + "`" + `#hindi` + "`" + `, match both Devanagari and common romanized spellings. Emoji and emoji shortcodes such as ` + "`" + `:middle_finger:` + "`" + ` are matched too.",
         "placeholder": "",
         "default": "4r5e,5h1t,5hit,a55,anal,anus,ar5e,arrse,arse,ass(es)?,ass[-]?fucker,assfukka,assholes?,asswhole,a_s_s,b!tch,b17ch,b1tch,ballbag,ballsack,bastard,bestiality,bellend,bestial,bi+ch,biatch,bitch,bitcher,bitchers,bitches,bitchin,bitching,bloody,blow[ ]?jobs?,boiolas,bollock,bollok,boner,b[o0][o0]+bs?,breasts,buceta,bugger,bum,bunny fucker,butt,butt[ ]?hole,buttmuch,buttplug,c[0o]cks?,c0cksucker,carpet muncher,cawk,chink,cipa,cl[i1]t,clitoris,clits,cnut,cock-sucker,cockface,cockhead,cockmunch,cockmuncher,cocksucks?,cocksucked,cocksucker,cocksucking,cocksuka,cocksukka,cok,cokmuncher,coksucka,coon,cox,crap,cums?,cummer,cumming,cumshot?,cunilingus,cunillingus,cunnilingus,cunt,cuntlick,cuntlicker,cuntlicking,cunts,cyalis,cyberfuc,cyberfuck,cyberfucked,cyberfucker,cyberfuckers,cyberfucking,d1ck,damn,dick,dickhead,dildo,dildos,dink,dinks,dirsa,dlck,dog-fucker,doggin,dogging,donkeyribber,doosh,duche,dyke,ejaculate,ejaculated,ejaculates,ejaculating,ejaculatings,ejaculation,ejakulate,f[[:space:]]*u[[:space:]]*c[[:space:]]*k,f[[:space:]]*u[[:space:]]*c[[:space:]]*k[[:space:]]*e[[:space:]]*r,f4nny,fag,fagging,faggitt,faggot,faggs,fagot,fagots,fags,fanny,fannyflaps,fannyfucker,fanyy,fatass,fcuk,fcuker,fcuking,feck,fecker,felching,fellate,fellatio,fingerfuck,fingerfucked,fingerfucker,fingerfuckers,fingerfucking,fingerfucks,fistfuck,fistfucked,fistfucker,fistfuckers,fistfucking,fistfuckings,fistfucks,flange,fook,fooker,fuck,fucka,fucked,fucker,fuckers,fuckhead,fuckheads,fuckin,fucking,fuckings,fuckingshitmother[[:space:]]*fucker,fuckme,fucks,fuckwhit,fuckwit,fudge packer,fudgepacker,fuk,fuker,fukker,fukkin,fuks,fukwhit,fukwit,fux,fux0r,f_u_c_k,gangbang,gangbanged,gangbangs,gaylord,gaysex,goatse,God,god-dam,god-damned,goddamn,goddamned,hardcoresex,hell,heshe,hoar,hoare,hoer,homo,hore,horniest,horny,hotsex,jack-off,jackoff,jap,jerk-off,jism,jiz,jizm,jizz,kawk,knob,knobead,knobed,knobend,knobhead,knobjocky,knobjokey,kock,kondum,kondums,kum,kummer,kumming,kums,kunilingus,l3i\\+ch,l3itch,labia,lust,lusting,m0f0,m0fo,m[a4][s5]terb(at[3e]|8),ma5terbate,masochist,master-bate,masterbations?,mo-fo,mof[o0],motha[[:space:]]*fuck,motha[[:space:]]*fuckas?,motha[[:space:]]*fuckaz,motha[[:space:]]*fucked,motha[[:space:]]*fuckers?,motha[[:space:]]*fuckin,motha[[:space:]]*fucking,motha[[:space:]]*fuckings,motha[[:space:]]*fucks,mother[[:space:]]*fuck,mother[[:space:]]*fucked,mother fucker,mother fuckers,mother fuckin,mother fucking,mother fuckings,mother fuckka,mother fucks,mother[[:space:]]*fucker,mother[[:space:]]*fuckers,mother[[:space:]]*fuckin,mother[[:space:]]*fucking,mother[[:space:]]*fuckings,mother[[:space:]]*fuckka,mother[[:space:]]*fucks,muff,mutha,muthafecker,muthafuckker,muther,mutherfucker,n[i1]gg[ea3]r?s?,niggaz,nob,nob jokey,nobhead,nobjocky,nobjokey,numbnuts,nutsack,orgasims?,orgasms?,p[o0]rno?s?,pawn,pecker,penis,penisfucker,phonesex,phuck,phuk,phuked,phuking,phukked,phukking,phuks,phuq,pigfucker,pimpis,piss,pissed,pisser,pissers,pisses,pissflaps,pissin,pissing,pissoff,poop,pornography,prick,pricks,pron,pube,pusse,puss[iy]e?s?,rectum,retard,rimjaw,rimming,s[[:space:]]*h[[:space:]]*i[[:space:]]*t,s\\.o\\.b\\.,sadist,schlong,screwing,scroat,scrote,scrotum,semen,sex,shag,shagger,shaggin,shagging,shemale,sh[i1!][t+]s?,shitdick,shite,shited,shitey,shitfuck,shitfull,shithead,shiting,shitings,shitted,shitter,shitters,shitting,shittings,shitty,skank,sluts?,smegma,smut,snatch,son-of-a-bitch,spac,spunk,t1tt1e5,t1tties,teets,teez,testical,testicle,tits?,titfuck,titt,tittie5,tittiefucker,titties?,tittyfuck,tittywank,titwank,tosser,turd,tw[4a]t,twathead,twatty,twunt,twunter,v14gra,v1gra,vagina,viagra,vulva,w00se,wang,wank,wanker,wanky,whoar,whores?,willies,willy,xrated,x[[:space:]]*x[[:space:]]*x.",
         "hosting": ""
+      },
+      {
+        "key": "ScopedPolicies",
+        "display_name": "Policies:",
+        "type": "longtext",
+        "help_text": "A JSON list of policies layered over the settings above, each applying to the posts that match all of its selectors: a ` + "`" + `team` + "`" + ` or a ` + "`" + `channel` + "`" + ` by name or ID, ` + "`" + `channel_types` + "`" + ` (` + "`" + `public` + "`" + `, ` + "`" + `private` + "`" + `, ` + "`" + `direct` + "`" + `, ` + "`" + `group` + "`" + `) and ` + "`" + `authors` + "`" + ` (` + "`" + `system_admin` + "`" + `, ` + "`" + `team_admin` + "`" + `, ` + "`" + `guest` + "`" + `, ` + "`" + `bot` + "`" + `, ` + "`" + `webhook` + "`" + `, ` + "`" + `slash_command` + "`" + `, ` + "`" + `system` + "`" + `), with ` + "`" + `bot_ids` + "`" + ` restricting ` + "`" + `bot` + "`" + ` to some bots. Each policy can add terms with ` + "`" + `add_words` + "`" + `, stop filtering terms or whole categories with ` + "`" + `remove_words` + "`" + `, e.g. ` + "`" + `category=mild` + "`" + `, never censor words with ` + "`" + `allow_words` + "`" + `, and override the ` + "`" + `action` + "`" + ` (` + "`" + `censor` + "`" + `, ` + "`" + `reject` + "`" + ` or ` + "`" + `exempt` + "`" + `) and the ` + "`" + `censor_style` + "`" + `. More specific policies override the others. E.g., ` + "`" + `[{\"team\": \"support\", \"action\": \"reject\"}, {\"channel_types\": [\"direct\"], \"action\": \"exempt\"}]` + "`" + `",
+        "placeholder": "",
+        "default": "",
+        "hosting": ""
+      },
+      {
+        "key": "ExemptUsers",
+        "display_name": "Exempt Users:",
+        "type": "text",
+        "help_text": "The user IDs or usernames whose posts and file uploads are never filtered, separated by commas, e.g. staff who must quote offending content verbatim.",
+        "placeholder": "E.g., alice, bob",
+        "default": "",
+        "hosting": ""
+      },
+      {
+        "key": "ExemptGroups",
+        "display_name": "Exempt Groups:",
+        "type": "text",
+        "help_text": "The names or IDs of the LDAP or custom groups whose members' posts and file uploads are never filtered, separated by commas.",
+        "placeholder": "E.g., legal, trust-and-safety",
+        "default": "",
+        "hosting": ""
+      },
+      {
+        "key": "ExemptRoles",
+        "display_name": "Exempt Roles:",
+        "type": "text",
+        "help_text": "The team or channel roles whose posts and file uploads are never filtered in that team or channel, separated by commas. Exemptions are cached for 5 minutes.",
+        "placeholder": "E.g., team_admin, channel_admin",
+        "default": "",
+        "hosting": ""
+      },
+      {
+        "key": "StemmingLanguages",
+        "display_name": "Stemming Languages:",
+        "type": "text",
+        "help_text": "Optional comma-separated list of languages whose inflected forms are matched, so that e.g. ` + "`" + `fuck` + "`" + ` also matches ` + "`" + `fucking` + "`" + ` and ` + "`" + `fucked` + "`" + `. Supported: danish, dutch, english, finnish, french, german, hungarian, italian, norwegian, portuguese, romanian, russian, spanish, swedish and turkish. Only plain words are stemmed, each in the first language or in the language of its ` + "`" + `lang` + "`" + ` option, e.g. ` + "`" + `joder#lang=spanish` + "`" + `; add the ` + "`" + `nostem` + "`" + ` option to a word whose stem collides with innocent words.",
+        "placeholder": "E.g., english, spanish",
+        "default": null,
+        "hosting": ""
+      },
+      {
+        "key": "FuzzyMatching",
+        "display_name": "Fuzzy Matching:",
+        "type": "bool",
+        "help_text": "When true, misspellings of plain words are also matched by edit distance, e.g. ` + "`" + `biatch` + "`" + ` for ` + "`" + `bitch` + "`" + `. The first letter must match, words shorter than four letters are never fuzzy matched and common English words are never matched. Add the ` + "`" + `nofuzzy` + "`" + ` option to a word that causes false positives.",
+        "placeholder": "",
+        "default": false,
+        "hosting": ""
+      },
+      {
+        "key": "FuzzyMaxDistance",
+        "display_name": "Fuzzy Match Maximum Distance:",
+        "type": "number",
+        "help_text": "The maximum number of edits (insertions, deletions, substitutions and transpositions) allowed for a fuzzy match. Words shorter than ten letters allow a single edit that swaps two letters or adds or drops a vowel; longer words allow one edit for every five letters, up to this maximum.",
+        "placeholder": "",
+        "default": 2,
+        "hosting": ""
+      },
+      {
+        "key": "PhoneticMinLength",
+        "display_name": "Phonetic Match Minimum Length:",
+        "type": "number",
+        "help_text": "The shortest word, in letters, that is matched against the words marked with the ` + "`" + `phonetic` + "`" + ` option. Shorter words sound alike too often to be matched safely.",
+        "placeholder": "",
+        "default": 4,
+        "hosting": ""
+      },
+      {
+        "key": "CompoundSplitting",
+        "display_name": "Split Compound Words:",
+        "type": "bool",
+        "help_text": "When true, hashtags, camelCase, snake_case and concatenated words are split into sub-words that are matched on their own, e.g. ` + "`" + `fuck` + "`" + ` in ` + "`" + `#FuckMondays` + "`" + `, ` + "`" + `fuckThis` + "`" + ` or ` + "`" + `fuck_this` + "`" + `. Only the offending sub-word is censored.",
+        "placeholder": "",
+        "default": false,
+        "hosting": ""
+      },
+      {
+        "key": "CompoundSplittingWords",
+        "display_name": "Compound Splitting Words:",
+        "type": "text",
+        "help_text": "Optional comma-separated list of words, in addition to the built-in list of common English words and the bad words, that concatenated words such as ` + "`" + `fuckstandup` + "`" + ` can be split into.",
+        "placeholder": "E.g., standup, retro",
+        "default": null,
+        "hosting": ""
+      },
+      {
+        "key": "Decoders",
+        "display_name": "Decoders:",
+        "type": "text",
+        "help_text": "Optional comma-separated list of encodings to decode messages from before matching, to catch evasions such as ` + "`" + `kcuf` + "`" + ` or ` + "`" + `shpx` + "`" + `. Supported: reversed, rot13, upside-down, mirrored and base64. The original encoded text is censored, and each detection is logged with the encoding it was decoded from.",
+        "placeholder": "E.g., reversed, rot13, base64",
+        "default": null,
+        "hosting": ""
+      },
+      {
+        "key": "MarkdownSkipCodeBlocks",
+        "display_name": "Skip Code Blocks:",
+        "type": "bool",
+        "help_text": "When true, fenced and indented code blocks are not scanned, so pasted code and logs are left untouched.",
+        "placeholder": "",
+        "default": true,
+        "hosting": ""
+      },
+      {
+        "key": "MarkdownSkipInlineCode",
+        "display_name": "Skip Inline Code:",
+        "type": "bool",
+        "help_text": "When true, inline code such as ` + "`" + `` + "`" + ` ` + "`" + `path/to/file` + "`" + ` ` + "`" + `` + "`" + ` is not scanned.",
+        "placeholder": "",
+        "default": true,
+        "hosting": ""
+      },
+      {
+        "key": "MarkdownSkipLinkURLs",
+        "display_name": "Skip Link URLs:",
+        "type": "bool",
+        "help_text": "When true, the URLs of links, images and autolinked addresses are not scanned, so links keep working.",
+        "placeholder": "",
+        "default": true,
+        "hosting": ""
+      },
+      {
+        "key": "MarkdownSkipLinkText",
+        "display_name": "Skip Link Text:",
+        "type": "bool",
+        "help_text": "When true, the text of links is not scanned.",
+        "placeholder": "",
+        "default": false,
+        "hosting": ""
+      },
+      {
+        "key": "MarkdownSkipQuotes",
+        "display_name": "Skip Quotes:",
+        "type": "bool",
+        "help_text": "When true, block quotes are not scanned.",
+        "placeholder": "",
+        "default": false,
+        "hosting": ""
+      },
+      {
+        "key": "FilterFileUploads",
+        "display_name": "Filter File Uploads:",
+        "type": "bool",
+        "help_text": "When true, the names of uploaded files and the text of .txt, .md, .csv, .json, .log and .docx files are checked. Files whose text contains profanity are rejected.",
+        "placeholder": "",
+        "default": false,
+        "hosting": ""
+      },
+      {
+        "key": "FileNameAction",
+        "display_name": "Profane File Names:",
+        "type": "dropdown",
+        "help_text": "What to do with uploaded files whose name contains profanity.",
+        "placeholder": "",
+        "default": "rename",
+        "options": [
+          {
+            "display_name": "Rename the file",
+            "value": "rename"
+          },
+          {
+            "display_name": "Reject the upload",
+            "value": "reject"
+          }
+        ],
+        "hosting": ""
+      },
+      {
+        "key": "FileScanMaxSizeKB",
+        "display_name": "File Scan Maximum Size (KB):",
+        "type": "number",
+        "help_text": "The text of uploaded files larger than this size is not checked, only their name.",
+        "placeholder": "",
+        "default": 1024,
+        "hosting": ""
+      },
+      {
+        "key": "FilterChannels",
+        "display_name": "Filter Channels:",
+        "type": "bool",
+        "help_text": "When true, the display names, headers and purposes of channels are checked when channels are created and updated.",
+        "placeholder": "",
+        "default": false,
+        "hosting": ""
+      },
+      {
+        "key": "ChannelAction",
+        "display_name": "Profane Channels:",
+        "type": "dropdown",
+        "help_text": "What to do with channel display names, headers and purposes that contain profanity. **Revert** restores the previous header or purpose, or clears it when the channel is created; display names are censored when they cannot be reverted.",
+        "placeholder": "",
+        "default": "censor",
+        "options": [
+          {
+            "display_name": "Censor",
+            "value": "censor"
+          },
+          {
+            "display_name": "Revert",
+            "value": "revert"
+          }
+        ],
+        "hosting": ""
+      },
+      {
+        "key": "FilterProfiles",
+        "display_name": "Filter Profiles:",
+        "type": "bool",
+        "help_text": "When true, the usernames, nicknames, first and last names, positions and custom statuses of new users and, periodically, of existing users are checked and reported to the moderators.",
+        "placeholder": "",
+        "default": false,
+        "hosting": ""
+      },
+      {
+        "key": "ProfileAction",
+        "display_name": "Profane Profiles:",
+        "type": "dropdown",
+        "help_text": "What to do with profile fields that contain profanity. Usernames cannot be reset and are always flagged for review.",
+        "placeholder": "",
+        "default": "flag",
+        "options": [
+          {
+            "display_name": "Flag for review",
+            "value": "flag"
+          },
+          {
+            "display_name": "Reset",
+            "value": "reset"
+          }
+        ],
+        "hosting": ""
+      },
+      {
+        "key": "FilterReactions",
+        "display_name": "Filter Reactions:",
+        "type": "bool",
+        "help_text": "When true, reactions with emoji whose names contain profanity are removed, and the custom emoji are periodically checked and reported to the moderators.",
+        "placeholder": "",
+        "default": false,
+        "hosting": ""
+      },
+      {
+        "key": "SweepIntervalHours",
+        "display_name": "Sweep Interval (hours):",
+        "type": "number",
+        "help_text": "How often the profiles of existing users and the custom emoji are checked. Set to 0 to only check new users and reactions.",
+        "placeholder": "",
+        "default": 24,
+        "hosting": ""
+      },
+      {
+        "key": "ModeratorUsernames",
+        "display_name": "Moderators:",
+        "type": "text",
+        "help_text": "The usernames of the moderators that are notified by direct message about censored channels, profiles and custom emoji, separated by commas.",
+        "placeholder": "E.g., alice, bob",
+        "default": "",
+        "hosting": ""
+      },
+      {
+        "key": "EmojiReplacement",
+        "display_name": "Emoji Replacement:",
+        "type": "text",
+        "help_text": "The emoji that replaces emoji and emoji shortcodes matching the bad words list when censoring. Leave empty to mask them with the censor character instead.",
+        "placeholder": "E.g., :see_no_evil:",
+        "default": ":see_no_evil:",
+        "hosting": ""
+      },
+      {
+        "key": "JapaneseDictionary",
+        "display_name": "Japanese Dictionary:",
+        "type": "dropdown",
+        "help_text": "The system dictionary used to split Japanese messages into words. UniDic segments modern and colloquial Japanese more accurately than IPA, but is not built into the plugin: bundle the ` + "`" + `uni.dict` + "`" + ` file of [kagome-dict](https://github.com/ikawaha/kagome-dict) in the plugin's ` + "`" + `assets/` + "`" + ` directory, or copy it to the file store under ` + "`" + `plugins/mattermost-profanity-filter/` + "`" + `, before selecting it. IPA is used if the file is not found.",
+        "placeholder": "",
+        "default": "ipa",
+        "options": [
+          {
+            "display_name": "IPA",
+            "value": "ipa"
+          },
+          {
+            "display_name": "UniDic",
+            "value": "uni"
+          }
+        ],
+        "hosting": ""
+      },
+      {
+        "key": "JapaneseTokenizerMode",
+        "display_name": "Japanese Tokenizer Mode:",
+        "type": "dropdown",
+        "help_text": "**Normal** splits messages into regular words. **Search** additionally splits long compound nouns into their parts. **Extended** additionally splits unknown words into single characters.",
+        "placeholder": "",
+        "default": "normal",
+        "options": [
+          {
+            "display_name": "Normal",
+            "value": "normal"
+          },
+          {
+            "display_name": "Search",
+            "value": "search"
+          },
+          {
+            "display_name": "Extended",
+            "value": "extended"
+          }
+        ],
+        "hosting": ""
+      },
+      {
+        "key": "JapaneseMatchStrictness",
+        "display_name": "Japanese Match Strictness:",
+        "type": "dropdown",
+        "help_text": "How strictly Japanese words must line up with the words of a message. **Token exact** only matches a whole word, **Token sequence** matches one or more whole words and **Substring** also matches inside longer words, which causes false positives for short words. Individual words can override this with the ` + "`" + `exact` + "`" + `, ` + "`" + `sequence` + "`" + ` or ` + "`" + `compound` + "`" + ` options.",
+        "placeholder": "",
+        "default": "token-sequence",
+        "options": [
+          {
+            "display_name": "Token exact",
+            "value": "token-exact"
+          },
+          {
+            "display_name": "Token sequence",
+            "value": "token-sequence"
+          },
+          {
+            "display_name": "Substring",
+            "value": "substring"
+          }
+        ],
+        "hosting": ""
+      },
+      {
+        "key": "JapaneseUserDictionary",
+        "display_name": "Japanese User Dictionary:",
+        "type": "text",
+        "help_text": "Optional name of a [Kagome user dictionary](https://github.com/ikawaha/kagome/wiki/User-dictionary) with custom compounds and slang. The dictionary is read from the plugin's KV store under this key or, if not found there, from ` + "`" + `plugins/mattermost-profanity-filter/\u003cname\u003e` + "`" + ` in the file store. It is reloaded whenever the configuration is saved.",
+        "placeholder": "",
+        "default": null,
+        "hosting": ""
       }
     ],
     "sections": null
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost/server/public/shared/markdown"
+)
+
+// markdownRegionKind flags the kinds of markdown a region of a message belongs to. A region can
+// belong to several kinds at once, e.g. link text inside a quote.
+type markdownRegionKind int
+
+const (
+	markdownCodeBlock markdownRegionKind = 1 << iota
+	markdownInlineCode
+	markdownLinkURL
+	markdownLinkText
+	markdownQuote
+)
+
+// markdownRegion is a span of a message holding text, code or a URL, as opposed to markdown syntax
+type markdownRegion struct {
+	Start, End int
+	Kind       markdownRegionKind
+}
+
+// markdownRegions parses a message as Mattermost markdown and returns the spans of its text, code
+// and URLs in order. Markdown syntax such as link brackets and code fences is left out, so that
+// censoring the regions cannot break the structure of the message.
+func markdownRegions(message string) []markdownRegion {
+	var regions []markdownRegion
+
+	// The parsed nodes are visited depth first, and f(nil) is called when leaving a node
+	var kinds []markdownRegionKind
+	kind := func() markdownRegionKind {
+		var k markdownRegionKind
+		for _, parent := range kinds {
+			k |= parent
+		}
+		return k
+	}
+	add := func(start, end int, k markdownRegionKind) {
+		if end > start {
+			regions = append(regions, markdownRegion{Start: start, End: end, Kind: k})
+		}
+	}
+
+	// lastEnd is the end of the last region of the current paragraph, from which the code spans and
+	// emoji, whose position is not recorded by the parser, are searched for
+	lastEnd := 0
+	parsed := false
+
+	markdown.Inspect(message, func(node any) bool {
+		parsed = true
+		if node == nil {
+			kinds = kinds[:len(kinds)-1]
+			return true
+		}
+
+		var nodeKind markdownRegionKind
+		switch v := node.(type) {
+		case *markdown.BlockQuote:
+			nodeKind = markdownQuote
+		case *markdown.FencedCode:
+			for _, line := range v.RawCode {
+				add(line.Range.Position, line.Range.End, kind()|markdownCodeBlock)
+			}
+		case *markdown.IndentedCode:
+			for _, line := range v.RawCode {
+				add(line.Range.Position, line.Range.End, kind()|markdownCodeBlock)
+			}
+		case *markdown.Paragraph:
+			if len(v.Text) > 0 {
+				lastEnd = v.Text[0].Position
+			}
+		case *markdown.Text:
+			add(v.Range.Position, v.Range.End, kind())
+			lastEnd = max(lastEnd, v.Range.End)
+		case *markdown.CodeSpan:
+			if start, end, ok := findCodeSpan(message, lastEnd); ok {
+				add(start, end, kind()|markdownInlineCode)
+				lastEnd = end
+			}
+		case *markdown.Emoji:
+			shortcode := ":" + v.Name + ":"
+			if offset := strings.Index(message[lastEnd:], shortcode); offset >= 0 {
+				add(lastEnd+offset, lastEnd+offset+len(shortcode), kind())
+				lastEnd += offset + len(shortcode)
+			}
+		case *markdown.InlineLink:
+			add(v.RawDestination.Position, v.RawDestination.End, kind()|markdownLinkURL)
+			nodeKind = markdownLinkText
+		case *markdown.InlineImage:
+			add(v.RawDestination.Position, v.RawDestination.End, kind()|markdownLinkURL)
+			nodeKind = markdownLinkText
+		case *markdown.ReferenceLink, *markdown.ReferenceImage:
+			nodeKind = markdownLinkText
+		case *markdown.Autolink:
+			add(v.RawDestination.Position, v.RawDestination.End, kind()|markdownLinkURL)
+			lastEnd = max(lastEnd, v.RawDestination.End)
+		}
+
+		kinds = append(kinds, nodeKind)
+		return true
+	})
+
+	// Messages too long to be parsed are scanned as plain text
+	if !parsed {
+		return []markdownRegion{{Start: 0, End: len(message)}}
+	}
+
+	sort.SliceStable(regions, func(i, j int) bool { return regions[i].Start < regions[j].Start })
+	return regions
+}
+
+// findCodeSpan finds the code of the first code span at or after position
+func findCodeSpan(message string, position int) (start, end int, ok bool) {
+	opening := strings.IndexByte(message[position:], '`')
+	if opening < 0 {
+		return 0, 0, false
+	}
+	fenceStart := position + opening
+	start = fenceStart
+	for start < len(message) && message[start] == '`' {
+		start++
+	}
+	fence := start - fenceStart
+
+	// The code span ends at the next run of as many backticks
+	for end = start; end < len(message); {
+		closing := strings.IndexByte(message[end:], '`')
+		if closing < 0 {
+			return 0, 0, false
+		}
+		end += closing
+		run := end
+		for run < len(message) && message[run] == '`' {
+			run++
+		}
+		if run-end == fence {
+			return start, end, true
+		}
+		end = run
+	}
+	return 0, 0, false
+}
+
+// markdownScanView joins the regions of a message that are scanned into a single text, separated by
+// whitespace, that remembers where each of its bytes came from
+func markdownScanView(message string, regions []markdownRegion, skipped markdownRegionKind) normalizedText {
+	var builder normalizedTextBuilder
+	previous := -1
+	for _, region := range regions {
+		if region.Kind&skipped != 0 {
+			continue
+		}
+		// Separate the regions unless there already is whitespace between them
+		if previous >= 0 && !unicode.IsSpace(rune(message[previous-1])) && !unicode.IsSpace(rune(message[region.Start])) {
+			builder.add(" ", region.Start, region.Start)
+		}
+		previous = region.End
+		for i, r := range message[region.Start:region.End] {
+			builder.add(string(r), region.Start+i, region.Start+i+utf8.RuneLen(r))
+		}
+	}
+	return builder.build()
+}
+
+// originalDetections maps detections found in the view back onto the message. A detection that
+// spans several regions is split into one detection per region, so that the markdown syntax
+// between the regions is never censored.
+func (n normalizedText) originalDetections(message string, detections []detection) []detection {
+	var mapped []detection
+	for _, d := range detections {
+		start, end := -1, -1
+		flush := func() {
+			if start >= 0 {
+				piece := d
+				piece.Word, piece.Start, piece.End = message[start:end], start, end
+				mapped = append(mapped, piece)
+			}
+			start, end = -1, -1
+		}
+
+		for i := d.Start; i < d.End; i++ {
+			if n.starts[i] == n.ends[i] {
+				// Separator between two regions
+				flush()
+				continue
+			}
+			if start >= 0 && n.starts[i] > end {
+				flush()
+			}
+			if start < 0 {
+				start = n.starts[i]
+			}
+			end = max(end, n.ends[i])
+		}
+		flush()
+	}
+	return mapped
+}
+
+// skippedMarkdownRegions returns the kinds of markdown regions the configuration skips
+func (c *configuration) skippedMarkdownRegions() markdownRegionKind {
+	var skipped markdownRegionKind
+	if c.MarkdownSkipCodeBlocks {
+		skipped |= markdownCodeBlock
+	}
+	if c.MarkdownSkipInlineCode {
+		skipped |= markdownInlineCode
+	}
+	if c.MarkdownSkipLinkURLs {
+		skipped |= markdownLinkURL
+	}
+	if c.MarkdownSkipLinkText {
+		skipped |= markdownLinkText
+	}
+	if c.MarkdownSkipQuotes {
+		skipped |= markdownQuote
+	}
+	return skipped
+}
+
+// detectMarkdownProfanityWords detects the bad words of the term set in the regions of a markdown
+// message that are scanned, locating them in the message itself
+func (p *Plugin) detectMarkdownProfanityWords(message string, terms *termSet) []detection {
+	configuration := p.getConfiguration()
+
+	regions := markdownRegions(message)
+	view := markdownScanView(message, regions, configuration.skippedMarkdownRegions())
+	return markVerbatimDetections(view.originalDetections(message, p.detectAllProfanityWords(view.text, terms)), regions)
+}
+
+// markVerbatimDetections marks the detections in code and URLs, where markdown is not interpreted
+func markVerbatimDetections(detections []detection, regions []markdownRegion) []detection {
+	for i, d := range detections {
+		for _, region := range regions {
+			if region.Start <= d.Start && d.End <= region.End {
+				detections[i].Verbatim = region.Kind&(markdownCodeBlock|markdownInlineCode|markdownLinkURL) != 0
+				break
+			}
+		}
+	}
+	return detections
+}
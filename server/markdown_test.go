@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestMarkdownRegions(t *testing.T) {
+	newPlugin := func(t *testing.T, config *configuration) *Plugin {
+		config.CensorCharacter = "*"
+		config.BadWordsList = "fuck,fuck you"
+		return newTestPlugin(t, config)
+	}
+
+	skipAll := &configuration{
+		MarkdownSkipCodeBlocks: true,
+		MarkdownSkipInlineCode: true,
+		MarkdownSkipLinkURLs:   true,
+		MarkdownSkipLinkText:   true,
+		MarkdownSkipQuotes:     true,
+	}
+
+	testCases := []struct {
+		name           string
+		config         configuration
+		input          string
+		expectedOutput string
+	}{
+		{"everything is scanned by default", configuration{}, "`fuck` [fuck](https://fuck.com)", "`****` [****](https://****.com)"},
+		{"fenced code block skipped", *skipAll, "fuck\n```\nfuck.log\n```", "****\n```\nfuck.log\n```"},
+		{"fenced code block scanned", configuration{}, "```\nfuck\n```", "```\n****\n```"},
+		{"indented code block skipped", *skipAll, "fuck\n\n    fuck()", "****\n\n    fuck()"},
+		{"inline code skipped", *skipAll, "fuck `fuck` fuck", "**** `fuck` ****"},
+		{"inline code with double backticks", configuration{MarkdownSkipInlineCode: true}, "``a ` fuck`` fuck", "``a ` fuck`` ****"},
+		{"link URL skipped", configuration{MarkdownSkipLinkURLs: true}, "[fuck](https://fuck.com/fuck)", "[****](https://fuck.com/fuck)"},
+		{"autolink skipped", configuration{MarkdownSkipLinkURLs: true}, "see https://www.fuck.com/x fuck", "see https://www.fuck.com/x ****"},
+		{"image URL skipped", configuration{MarkdownSkipLinkURLs: true}, "![fuck](/files/fuck.png)", "![****](/files/fuck.png)"},
+		{"link text skipped", configuration{MarkdownSkipLinkText: true}, "[fuck](https://fuck.com) fuck", "[fuck](https://****.com) ****"},
+		{"quote skipped", configuration{MarkdownSkipQuotes: true}, "> fuck\n\nfuck", "> fuck\n\n****"},
+		{"quote scanned", configuration{}, "> fuck", "> ****"},
+		{"phrase across formatting keeps the syntax", configuration{}, "fuck [you](https://example.com)", "*****[***](https://example.com)"},
+		{"escaped characters keep their escapes", configuration{}, "\\*fuck\\*", "\\*****\\*"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.config
+			p := newPlugin(t, &config)
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+}
+
+func TestFindCodeSpan(t *testing.T) {
+	testCases := []struct {
+		message       string
+		expectedCode  string
+		expectedFound bool
+	}{
+		{"a `code` b", "code", true},
+		{"a ``co`de`` b", "co`de", true},
+		{"a `unclosed", "", false},
+		{"no code", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			start, end, ok := findCodeSpan(tc.message, 0)
+			assert.Equal(t, tc.expectedFound, ok)
+			if ok {
+				assert.Equal(t, tc.expectedCode, tc.message[start:end])
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // createMockPlugin creates a plugin with a mocked API for testing
@@ -14,16 +15,54 @@ func createMockPlugin(_ *testing.T, config *configuration) *Plugin {
 	// Mock the LoadPluginConfiguration method with proper parameter matching
 	api.On("LoadPluginConfiguration", mock.AnythingOfType("*main.configuration")).Return(nil).Run(func(args mock.Arguments) {
 		dest := args.Get(0).(*configuration)
-		// Copy the mock config fields to the destination
-		dest.CensorCharacter = config.CensorCharacter
-		dest.RejectPosts = config.RejectPosts
-		dest.BadWordsList = config.BadWordsList
-		dest.ExcludeBots = config.ExcludeBots
-		dest.WarningMessage = config.WarningMessage
+		*dest = *config
 	})
 
+	allowDebugLogging(api)
+
 	plugin := &Plugin{}
 	plugin.SetAPI(api)
 
 	return plugin
 }
+
+// newTestPlugin creates a plugin with the given configuration and its terms compiled
+func newTestPlugin(t *testing.T, config *configuration) *Plugin {
+	p := &Plugin{configuration: config}
+	require.NoError(t, p.compileWordRegexes(config.BadWordsList))
+	return p
+}
+
+// newTestPluginWithAPI creates a plugin with the given configuration, its terms compiled and a
+// mocked API that allows debug logging
+func newTestPluginWithAPI(t *testing.T, config *configuration) (*Plugin, *plugintest.API) {
+	api := &plugintest.API{}
+	allowDebugLogging(api)
+
+	p := &Plugin{configuration: config}
+	p.SetAPI(api)
+	require.NoError(t, p.compileWordRegexes(config.BadWordsList))
+	return p, api
+}
+
+// defaultBadWordsList returns the default of the bad words list setting from the manifest
+func defaultBadWordsList(t *testing.T) string {
+	for _, setting := range manifest.SettingsSchema.Settings {
+		if setting.Key == "BadWordsList" {
+			return setting.Default.(string)
+		}
+	}
+	require.FailNow(t, "the manifest has no bad words list setting")
+	return ""
+}
+
+// allowDebugLogging allows debug logging with any number of key-value pairs
+func allowDebugLogging(api *plugintest.API) {
+	for pairs := 0; pairs <= 8; pairs++ {
+		arguments := make([]interface{}, 1+2*pairs)
+		for i := range arguments {
+			arguments[i] = mock.Anything
+		}
+		api.On("LogDebug", arguments...).Maybe()
+	}
+}
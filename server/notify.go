@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/pkg/errors"
+)
+
+// botUsername is the username of the bot notifications are sent from
+const botUsername = "profanity-filter"
+
+// reportMaxRunes is the length of the longest message sent with a report, which is split into
+// several messages beyond that
+const reportMaxRunes = model.PostMessageMaxRunesV2
+
+// OnActivate ensures the bot that notifies users and moderators exists
+func (p *Plugin) OnActivate() error {
+	botID, err := p.API.EnsureBotUser(&model.Bot{
+		Username:    botUsername,
+		DisplayName: "Profanity Filter",
+		Description: "Notifies users and moderators about censored content.",
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to ensure the bot user")
+	}
+	p.botID = botID
+	return nil
+}
+
+// notifyUser sends a direct message from the bot to a user
+func (p *Plugin) notifyUser(userID, message string) {
+	if p.botID == "" || userID == "" {
+		return
+	}
+
+	channel, appErr := p.API.GetDirectChannel(p.botID, userID)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get the direct channel to notify a user", "user_id", userID, "err", appErr.Error())
+		return
+	}
+	if _, appErr := p.API.CreatePost(&model.Post{UserId: p.botID, ChannelId: channel.Id, Message: message}); appErr != nil {
+		p.API.LogWarn("Failed to notify a user", "user_id", userID, "err", appErr.Error())
+	}
+}
+
+// notifyModerators sends a direct message from the bot to each moderator
+func (p *Plugin) notifyModerators(message string) {
+	for _, username := range splitWordList(p.getConfiguration().ModeratorUsernames) {
+		user, appErr := p.API.GetUserByUsername(username)
+		if appErr != nil {
+			p.API.LogWarn("Failed to find a moderator to notify", "username", username, "err", appErr.Error())
+			continue
+		}
+		p.notifyUser(user.Id, message)
+	}
+}
+
+// notifyModeratorsReport sends a report to each moderator in as many messages as needed to stay
+// under the message length limit, repeating the header in each message
+func (p *Plugin) notifyModeratorsReport(header string, rows []string) {
+	for _, message := range splitReport(header, rows, reportMaxRunes) {
+		p.notifyModerators(message)
+	}
+}
+
+// splitReport joins the header and the rows of a report into messages of at most maxRunes runes,
+// never splitting a row. A row too long to fit with the header is sent with it on its own.
+func splitReport(header string, rows []string, maxRunes int) []string {
+	var messages []string
+	var message strings.Builder
+	length := 0
+	for _, row := range rows {
+		rowLength := utf8.RuneCountInString(row)
+		if length > 0 && length+rowLength > maxRunes {
+			messages = append(messages, message.String())
+			message.Reset()
+			length = 0
+		}
+		if length == 0 {
+			message.WriteString(header)
+			length = utf8.RuneCountInString(header)
+		}
+		message.WriteString(row)
+		length += rowLength
+	}
+	if length > 0 {
+		messages = append(messages, message.String())
+	}
+	return messages
+}
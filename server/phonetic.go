@@ -0,0 +1,108 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antzucaro/matchr"
+)
+
+// defaultPhoneticMinLength is the shortest word that is phonetically matched when the
+// PhoneticMinLength setting is not set
+const defaultPhoneticMinLength = 4
+
+// phoneticIndex maps the Double Metaphone codes of the terms marked with the phonetic option to the
+// terms
+type phoneticIndex struct {
+	codes map[string]string
+	// minLength is the shortest word of the text that is phonetically matched
+	minLength int
+}
+
+// phoneticSpellings replaces the letters that are spelled differently but sound the same, e.g.
+// "ph" and "f"
+var phoneticSpellings = strings.NewReplacer("ph", "f", "ck", "k", "q", "k", "y", "i", "z", "s")
+
+// phoneticSpelling spells a word the way it sounds, so that sound-alike spellings such as "phuck"
+// and "fuck" are spelled the same
+func phoneticSpelling(word string) string {
+	return phoneticSpellings.Replace(strings.ToLower(removeAccents(word)))
+}
+
+// phoneticMaxDistance returns the number of edits the sound-alike spelling of a word that sounds
+// like a term may differ from the term's by: one for every three letters and at least one, so that
+// words that merely share their consonants, such as "fake" for "fuck" or "shiite" for "shit", are
+// kept
+func phoneticMaxDistance(termWord string) int {
+	return max(utf8.RuneCountInString(termWord)/3, 1)
+}
+
+// phoneticCodes returns the primary and alternate Double Metaphone codes of a word
+func phoneticCodes(word string) []string {
+	primary, alternate := matchr.DoubleMetaphone(strings.ToLower(removeAccents(word)))
+	if alternate == "" || alternate == primary {
+		return []string{primary}
+	}
+	return []string{primary, alternate}
+}
+
+// compilePhoneticIndex computes the codes of the plain words marked with the phonetic option
+func compilePhoneticIndex(terms []term, minLength int) *phoneticIndex {
+	if minLength <= 0 {
+		minLength = defaultPhoneticMinLength
+	}
+
+	index := &phoneticIndex{codes: map[string]string{}, minLength: minLength}
+	for _, t := range terms {
+		if !t.hasOption(termOptionPhonetic) || isJapaneseWord(t.Word) || !isPlainWord(t.Word) {
+			continue
+		}
+		for _, code := range phoneticCodes(t.Word) {
+			if code != "" {
+				if _, ok := index.codes[code]; !ok {
+					index.codes[code] = t.Word
+				}
+			}
+		}
+	}
+
+	if len(index.codes) == 0 {
+		return nil
+	}
+	return index
+}
+
+// detectPhoneticWords matches every word of the text that sounds like a bad word marked with the
+// phonetic option and is spelled alike, e.g. "phuck" for "fuck". Common words such as "sheet" are
+// never matched.
+func (p *Plugin) detectPhoneticWords(text string, terms *termSet) []detection {
+	var detected []detection
+
+	index := terms.phoneticIndex
+	if index == nil {
+		return detected
+	}
+
+	for _, match := range stemmedWordRegex.FindAllStringIndex(text, -1) {
+		word := text[match[0]:match[1]]
+		if utf8.RuneCountInString(word) < index.minLength || isCommonWord(word) {
+			continue
+		}
+		for _, code := range phoneticCodes(word) {
+			termWord, ok := index.codes[code]
+			if !ok {
+				continue
+			}
+			termSpelling := phoneticSpelling(termWord)
+			if damerauLevenshtein(phoneticSpelling(word), termSpelling) <= phoneticMaxDistance(termSpelling) {
+				d := newDetection(text, match[0], match[1])
+				d.Strategy = "phonetic"
+				d.Term = termWord
+				detected = append(detected, d)
+				break
+			}
+		}
+	}
+
+	return detected
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestPhoneticMatching(t *testing.T) {
+	testCases := []struct {
+		name           string
+		wordList       string
+		minLength      int
+		input          string
+		expectedOutput string
+	}{
+		{"terms are not phonetic by default", "fuck", 0, "phuck", "phuck"},
+		{"sound-alike spelling", "fuck#phonetic", 0, "phuck you", "***** you"},
+		{"sound-alike inflection", "fucking#phonetic", 0, "PHUCKING", "********"},
+		{"vowel change", "shit#phonetic", 0, "shyt", "****"},
+		{"common words are kept", "shit#phonetic", 0, "shut shoot sheet", "shut shoot sheet"},
+		{"words spelled too differently are kept", "shit#phonetic,fuck#phonetic", 0, "sheath fake phaque", "sheath fake phaque"},
+		{"short terms allow a single edit", "shit#phonetic", 0, "shiite sheet shot", "shiite sheet shot"},
+		{"sound-alike letters are not edits", "fuck#phonetic,ass#phonetic", 3, "phuq azz", "**** ***"},
+		{"words below the minimum length are kept", "ass#phonetic", 0, "azz", "azz"},
+		{"lower minimum length", "ass#phonetic", 3, "azz", "***"},
+		{"different sound is kept", "fuck#phonetic", 0, "fork", "fork"},
+		{"only marked terms are phonetic", "fuck#phonetic,shit", 0, "phuck sheet", "***** sheet"},
+		{"regex terms are not phonetic", "fu+ck#phonetic", 0, "phuck", "phuck"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: tc.wordList, PhoneticMinLength: tc.minLength})
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+}
@@ -2,13 +2,14 @@ package main
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/ikawaha/kagome/v2/tokenizer"
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/pluginapi/cluster"
 )
 
 type Plugin struct {
@@ -21,12 +22,35 @@ type Plugin struct {
 	// setConfiguration for usage.
 	configuration *configuration
 
-	// Pre-compiled regex patterns for performance
-	asciiWordsRegex    *regexp.Regexp
-	japaneseWordsRegex *regexp.Regexp
+	// Pre-compiled terms of the bad words list
+	terms *termSet
 
 	// Pre-initialized Japanese tokenizer for performance
 	japaneseTokenizer *tokenizer.Tokenizer
+
+	// Mode the Japanese tokenizer analyzes text in
+	japaneseTokenizeMode tokenizer.TokenizeMode
+
+	// Encodings whose decoded views of the messages are also matched
+	decoders []string
+
+	// Policies of teams, channels, channel types and author types layered over the installation policy
+	scopedPolicies []scopedPolicy
+
+	// Exemptions of users in channels, resolved through the API
+	exemptionCache *exemptionCache
+
+	// Channels, teams and authors of posts, resolved through the API for the policies
+	scopeCache *scopeCache
+
+	// Censor style of each category of terms
+	censorCategoryStyles map[string]string
+
+	// ID of the bot notifications are sent from
+	botID string
+
+	// Scheduled sweep over the profiles of existing users and the custom emoji
+	sweepJob *cluster.Job
 }
 
 func (p *Plugin) FilterPost(post *model.Post) (*model.Post, string) {
@@ -37,8 +61,31 @@ func (p *Plugin) FilterPost(post *model.Post) (*model.Post, string) {
 		return post, ""
 	}
 
-	// Use hybrid detection system that separates ASCII and non-ASCII word detection for better multilingual support
-	detectedBadWords := p.detectAllProfanityWords(post.Message, configuration.BadWordsList)
+	// Leave the notifications and reports of the plugin's own bot, which quote the bad words
+	if p.botID != "" && post.UserId == p.botID {
+		return post, ""
+	}
+
+	// Leave the posts of exempt users, group members and roles unfiltered
+	if p.isExempt(post.UserId, post.ChannelId) {
+		return post, ""
+	}
+
+	// Layer the policies of the team, channel and author of the post over the installation policy
+	configuration = p.scopeConfiguration(configuration, post)
+	if configuration.exempt {
+		return post, ""
+	}
+
+	// Scan the message and the user-visible texts of the props with the same rules
+	texts := postTexts(post)
+	detections := make([][]detection, len(texts))
+	var detectedBadWords []string
+	for i, text := range texts {
+		detections[i] = p.detectText(post.ChannelId, text, configuration)
+		p.logDetections(post, text.Path, detections[i])
+		detectedBadWords = append(detectedBadWords, detectedWords(detections[i])...)
+	}
 
 	if len(detectedBadWords) == 0 {
 		return post, ""
@@ -54,14 +101,15 @@ func (p *Plugin) FilterPost(post *model.Post) (*model.Post, string) {
 		return nil, fmt.Sprintf("Profane word not allowed: %s", strings.Join(detectedBadWords, ", "))
 	}
 
-	// Use rune-based replacement for correct character count
-	for _, word := range detectedBadWords {
-		post.Message = strings.ReplaceAll(
-			post.Message,
-			word,
-			strings.Repeat(p.getConfiguration().CensorCharacter, runeLength(word)),
-		)
+	censor := p.newCensorer(configuration)
+	var censoredPaths []string
+	for i, text := range texts {
+		if len(detections[i]) > 0 {
+			text.set(censorDetections(text.Text, detections[i], censor))
+			censoredPaths = append(censoredPaths, text.Path)
+		}
 	}
+	p.logCensoredPaths(post, censoredPaths)
 
 	return post, ""
 }
@@ -79,43 +127,227 @@ func runeLength(s string) int {
 	return len([]rune(s))
 }
 
-// detectAllProfanityWords uses detection for ASCII and Japanese words
-func (p *Plugin) detectAllProfanityWords(text, wordList string) []string {
-	words := splitWordList(wordList)
-	asciiWords, japaneseWords := separateASCIIAndJapanese(words)
+// detection is a bad word found in a text, located by its byte span in the original text
+type detection struct {
+	// Word is the detected text as it appears in the original text
+	Word  string
+	Start int
+	End   int
+	// Strategy names the matching strategy that found the word, e.g. "regex" or "token-exact"
+	Strategy string
+	// Distance is the edit distance between the word and the bad word it was fuzzy matched with
+	Distance int
+	// Encoding names the encoding the word was decoded from, e.g. "rot13" or "base64"
+	Encoding string
+	// Replacement replaces the word when censoring instead of masking it, e.g. a neutral emoji
+	Replacement string
+	// Term is the bad word the text matched, when it differs from the detected text, e.g. "fuck"
+	// for the misspelling "fcuk"
+	Term string
+	// Category is the category of the matched term, e.g. "slurs"
+	Category string
+	// Verbatim marks a detection in code or a URL, where markdown is not interpreted
+	Verbatim bool
+}
+
+// newDetection creates a detection for the span [start, end) of text
+func newDetection(text string, start, end int) detection {
+	return detection{Word: text[start:end], Start: start, End: end}
+}
+
+// detectedWords returns the detected text of each detection
+func detectedWords(detections []detection) []string {
+	words := make([]string, 0, len(detections))
+	for _, d := range detections {
+		words = append(words, d.Word)
+	}
+	return words
+}
+
+// logDetections reports each detection of a text of a post together with the strategy that
+// matched it
+func (p *Plugin) logDetections(post *model.Post, path string, detections []detection) {
+	if p.API == nil {
+		return
+	}
+	for _, d := range detections {
+		keyValuePairs := []interface{}{"post_id", post.Id, "user_id", post.UserId, "path", path, "word", d.Word, "strategy", d.Strategy}
+		if d.Distance > 0 {
+			keyValuePairs = append(keyValuePairs, "distance", d.Distance)
+		}
+		if d.Encoding != "" {
+			keyValuePairs = append(keyValuePairs, "encoding", d.Encoding)
+		}
+		if d.Category != "" {
+			keyValuePairs = append(keyValuePairs, "category", d.Category)
+		}
+		p.API.LogDebug("Profanity detected", keyValuePairs...)
+	}
+}
+
+// mergeDetections appends the detections that do not overlap with an existing detection
+func mergeDetections(detected, more []detection) []detection {
+	for _, d := range more {
+		overlaps := false
+		for _, existing := range detected {
+			if d.Start < existing.End && existing.Start < d.End {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			detected = append(detected, d)
+		}
+	}
+	return detected
+}
+
+// censorDetections replaces every detected span of the text with its censored form. Overlapping
+// detections are censored as a single span.
+func censorDetections(text string, detections []detection, c censorer) string {
+	sorted := make([]detection, len(detections))
+	copy(sorted, detections)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var builder strings.Builder
+	position := 0
+	for _, d := range sorted {
+		if d.End <= position {
+			continue
+		}
+		start := d.Start
+		if start < position {
+			start = position
+		}
+		builder.WriteString(text[position:start])
+		builder.WriteString(c.censor(text[start:d.End], d))
+		position = d.End
+	}
+	builder.WriteString(text[position:])
 
-	var detected []string
+	return builder.String()
+}
+
+// detectAllProfanityWords uses detection for ASCII, Japanese and Hindi words, with the given terms
+func (p *Plugin) detectAllProfanityWords(text string, terms *termSet) []detection {
+	hindiTerms, otherTerms := separateHindiTerms(parseTerms(splitWordList(terms.wordList)))
+	emojiTerms, otherTerms := separateEmojiTerms(otherTerms)
+	asciiWords, _ := separateASCIIAndJapanese(termWords(otherTerms))
+	japaneseTerms := japaneseTermsOf(otherTerms)
+
+	var detected []detection
+
+	// Emoji and shortcodes: Matched first, so that whole emoji are replaced rather than masked
+	if len(emojiTerms) > 0 {
+		detected = append(detected, p.detectEmoji(text, terms)...)
+	}
 
 	// ASCII words: Use existing regex (fast & precise)
 	if len(asciiWords) > 0 {
-		detected = append(detected, p.detectASCIIWords(text, asciiWords)...)
+		detected = append(detected, p.detectASCIIWords(text, asciiWords, terms)...)
 	}
 
-	// Japanese words: Use tokenization + regex approach
-	if len(japaneseWords) > 0 {
-		detected = append(detected, p.detectJapaneseWordsWithTokenization(text, japaneseWords)...)
+	// Japanese words: Use tokenization + regex approach on normalized kana
+	if len(japaneseTerms) > 0 {
+		detected = append(detected, p.detectJapaneseWordsWithTokenization(text, japaneseTerms, terms)...)
 	}
 
+	// Japanese words marked for lemma matching: Also compare against each morpheme's dictionary form
+	if lemmaTerms := japaneseLemmaTerms(otherTerms); len(lemmaTerms) > 0 {
+		detected = mergeDetections(detected, p.detectJapaneseLemmas(text, lemmaTerms))
+	}
+
+	// Japanese words marked for reading matching: Also match their kana and romaji readings
+	if readingTerms := japaneseReadingTerms(otherTerms); len(readingTerms) > 0 {
+		detected = mergeDetections(detected, p.detectJapaneseReadings(text, readingTerms))
+	}
+
+	// Hindi words: Use script-aware word splitting + romanization normalization
+	if len(hindiTerms) > 0 {
+		detected = append(detected, p.detectHindiWords(text, terms)...)
+	}
+
+	// Stemming: Also match inflected forms of plain words
+	detected = mergeDetections(detected, p.detectStemmedWords(text, terms))
+
+	// Fuzzy matching: Also match misspellings of plain words
+	detected = mergeDetections(detected, p.detectFuzzyWords(text, terms))
+
+	// Phonetic matching: Also match words that sound like the terms marked with the phonetic option
+	detected = mergeDetections(detected, p.detectPhoneticWords(text, terms))
+
+	// Compound splitting: Also match the sub-words of hashtags, camelCase and concatenated words
+	detected = mergeDetections(detected, p.detectCompoundWords(text, terms))
+
+	// Decoders: Also match reversed, ROT13, flipped and base64 encoded words
+	detected = mergeDetections(detected, p.detectEncodedWords(text, terms))
+
 	return detected
 }
 
-// getASCIIWordsRegex returns the pre-compiled ASCII words regex pattern
-func (p *Plugin) getASCIIWordsRegex() *regexp.Regexp {
+// getTerms returns the pre-compiled terms of the bad words list, or no terms before they are
+// compiled
+func (p *Plugin) getTerms() *termSet {
 	p.configurationLock.RLock()
 	defer p.configurationLock.RUnlock()
-	return p.asciiWordsRegex
+	if p.terms == nil {
+		return &termSet{}
+	}
+	return p.terms
 }
 
-// getJapaneseWordsRegex returns the pre-compiled Japanese words regex pattern
-func (p *Plugin) getJapaneseWordsRegex() *regexp.Regexp {
+// getJapaneseTokenizer returns the pre-initialized Japanese tokenizer
+func (p *Plugin) getJapaneseTokenizer() *tokenizer.Tokenizer {
 	p.configurationLock.RLock()
 	defer p.configurationLock.RUnlock()
-	return p.japaneseWordsRegex
+	return p.japaneseTokenizer
 }
 
-// getJapaneseTokenizer returns the pre-initialized Japanese tokenizer
-func (p *Plugin) getJapaneseTokenizer() *tokenizer.Tokenizer {
+// getJapaneseTokenizeMode returns the mode the Japanese tokenizer analyzes text in
+func (p *Plugin) getJapaneseTokenizeMode() tokenizer.TokenizeMode {
 	p.configurationLock.RLock()
 	defer p.configurationLock.RUnlock()
-	return p.japaneseTokenizer
+	if p.japaneseTokenizeMode == 0 {
+		return tokenizer.Normal
+	}
+	return p.japaneseTokenizeMode
+}
+
+// getDecoders returns the encodings whose decoded views of the messages are matched
+func (p *Plugin) getDecoders() []string {
+	p.configurationLock.RLock()
+	defer p.configurationLock.RUnlock()
+	return p.decoders
+}
+
+// getCensorCategoryStyles returns the censor style of each category of terms
+func (p *Plugin) getCensorCategoryStyles() map[string]string {
+	p.configurationLock.RLock()
+	defer p.configurationLock.RUnlock()
+	return p.censorCategoryStyles
+}
+
+// getScopedPolicies returns the policies of teams, channels, channel types and author types
+func (p *Plugin) getScopedPolicies() []scopedPolicy {
+	p.configurationLock.RLock()
+	defer p.configurationLock.RUnlock()
+	return p.scopedPolicies
+}
+
+// getExemptionCache returns the exemptions of users in channels
+func (p *Plugin) getExemptionCache() *exemptionCache {
+	p.configurationLock.RLock()
+	defer p.configurationLock.RUnlock()
+	return p.exemptionCache
+}
+
+// getScopeCache returns the channels, teams and authors of posts resolved for the policies, or a
+// cache that remembers nothing before it is created
+func (p *Plugin) getScopeCache() *scopeCache {
+	p.configurationLock.RLock()
+	defer p.configurationLock.RUnlock()
+	if p.scopeCache == nil {
+		return &scopeCache{}
+	}
+	return p.scopeCache
 }
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+const (
+	// policyActionCensor censors the posts of a scope, whatever the installation action
+	policyActionCensor = "censor"
+	// policyActionReject rejects the posts of a scope, whatever the installation action
+	policyActionReject = "reject"
+	// policyActionExempt leaves the posts of a scope unfiltered
+	policyActionExempt = "exempt"
+
+	// removedCategoryPrefix removes every term of a category from a scope, e.g. "category=mild"
+	removedCategoryPrefix = termOptionCategory + termOptionValueSeparator
+
+	// scopeCacheTTL is how long the channels, teams and authors of posts are remembered, so that
+	// they are not looked up for every post
+	scopeCacheTTL = 5 * time.Minute
+
+	// scopeCacheMaxEntries bounds the number of cached channels, teams, authors and team admins
+	scopeCacheMaxEntries = 10000
+)
+
+// authorTypes are the types of post authors policies can select
+var authorTypes = map[string]bool{
+	"system_admin":  true,
+	"team_admin":    true,
+	"guest":         true,
+	"bot":           true,
+	"webhook":       true,
+	"slash_command": true,
+	"system":        true,
+}
+
+// channelTypes are the channel types policies can select, by name
+var channelTypes = map[string]model.ChannelType{
+	"public":  model.ChannelTypeOpen,
+	"private": model.ChannelTypePrivate,
+	"direct":  model.ChannelTypeDirect,
+	"group":   model.ChannelTypeGroup,
+}
+
+// scopedPolicy is the policy of a team, channel, channel type or author type, layered over the
+// installation policy. A policy applies to the posts matching all of its selectors. The word lists
+// are separated by commas like the bad words list.
+type scopedPolicy struct {
+	// Team is the name or ID of the team the policy applies to
+	Team string `json:"team"`
+	// Channel is the name or ID of the channel the policy applies to
+	Channel string `json:"channel"`
+	// ChannelTypes lists the types of the channels the policy applies to: "public", "private",
+	// "direct" and "group"
+	ChannelTypes []string `json:"channel_types"`
+	// Authors lists the types of the authors the policy applies to: "system_admin", "team_admin",
+	// "guest", "bot", "webhook", "slash_command" and "system"
+	Authors []string `json:"authors"`
+	// BotIDs restricts the "bot" author type to the bots with these user IDs
+	BotIDs []string `json:"bot_ids"`
+	// AddWords lists the terms that are also filtered in the scope, with their options
+	AddWords string `json:"add_words"`
+	// RemoveWords lists the terms that are not filtered in the scope, or their categories, e.g.
+	// "category=mild"
+	RemoveWords string `json:"remove_words"`
+	// AllowWords lists the words that are never censored in the scope, whichever term they match
+	AllowWords string `json:"allow_words"`
+	// Action is "censor", "reject" or "exempt", overriding Reject Posts
+	Action string `json:"action"`
+	// CensorStyle overrides the installation censor style
+	CensorStyle string `json:"censor_style"`
+
+	// terms are the compiled terms added by the policy, or nil if it adds none
+	terms *termSet
+}
+
+// parseScopedPolicies parses the JSON list of policies
+func parseScopedPolicies(setting string) ([]scopedPolicy, error) {
+	if strings.TrimSpace(setting) == "" {
+		return nil, nil
+	}
+
+	var policies []scopedPolicy
+	if err := json.Unmarshal([]byte(setting), &policies); err != nil {
+		return nil, fmt.Errorf("invalid scoped policies: %w", err)
+	}
+	for i, policy := range policies {
+		if policy.Team == "" && policy.Channel == "" && len(policy.ChannelTypes) == 0 && len(policy.Authors) == 0 {
+			return nil, fmt.Errorf("scoped policy %d applies to no team, channel, channel type or author", i)
+		}
+		for _, channelType := range policy.ChannelTypes {
+			if _, ok := channelTypes[strings.ToLower(channelType)]; !ok {
+				return nil, fmt.Errorf("scoped policy %d has an unsupported channel type %q", i, channelType)
+			}
+		}
+		for _, author := range policy.Authors {
+			if !authorTypes[strings.ToLower(author)] {
+				return nil, fmt.Errorf("scoped policy %d has an unsupported author type %q", i, author)
+			}
+		}
+		switch strings.ToLower(policy.Action) {
+		case "", policyActionCensor, policyActionReject, policyActionExempt:
+		default:
+			return nil, fmt.Errorf("scoped policy %d has an unsupported action %q", i, policy.Action)
+		}
+		if _, err := parseCensorStyle(policy.CensorStyle); err != nil {
+			return nil, fmt.Errorf("scoped policy %d: %w", i, err)
+		}
+	}
+
+	// More specific policies are layered last, so that they override the others
+	sort.SliceStable(policies, func(i, j int) bool { return policies[i].rank() < policies[j].rank() })
+	return policies, nil
+}
+
+// rank orders the policies from the least to the most specific: channel type, team and channel
+// policies, each followed by the policies that also select authors
+func (policy scopedPolicy) rank() int {
+	rank := 0
+	switch {
+	case policy.Channel != "":
+		rank = 4
+	case policy.Team != "":
+		rank = 2
+	}
+	if len(policy.Authors) > 0 {
+		rank++
+	}
+	return rank
+}
+
+// compilePolicyTerms compiles the terms added by each policy into a term set of its own, with the
+// settings of the installation, so that they never take matches from the terms of the bad words
+// list outside of the scope of the policy
+func compilePolicyTerms(config *configuration, policies []scopedPolicy) error {
+	for i, policy := range policies {
+		wordList := normalizeWordListCommas(policy.AddWords)
+		if len(splitWordList(wordList)) == 0 {
+			continue
+		}
+
+		terms, err := compileTermSet(config, wordList)
+		if err != nil {
+			return fmt.Errorf("failed to compile the words added by a scoped policy: %w", err)
+		}
+		policies[i].terms = terms
+	}
+	return nil
+}
+
+// matches checks if the policy applies to the post of a scope. Names are compared case
+// insensitively. The channel and team are only looked up when the policy needs more than their ID.
+func (policy scopedPolicy) matches(scope *postScope) bool {
+	if policy.Channel != "" && policy.Channel != scope.post.ChannelId {
+		if channel := scope.channel(); channel == nil || !strings.EqualFold(policy.Channel, channel.Name) {
+			return false
+		}
+	}
+	if policy.Team != "" {
+		channel := scope.channel()
+		if channel == nil {
+			return false
+		}
+		if policy.Team != channel.TeamId {
+			if team := scope.team(); team == nil || !strings.EqualFold(policy.Team, team.Name) {
+				return false
+			}
+		}
+	}
+	if len(policy.ChannelTypes) > 0 {
+		channel := scope.channel()
+		if channel == nil || !slices.ContainsFunc(policy.ChannelTypes, func(name string) bool {
+			return channelTypes[strings.ToLower(name)] == channel.Type
+		}) {
+			return false
+		}
+	}
+
+	if len(policy.Authors) > 0 {
+		return slices.ContainsFunc(policy.Authors, func(author string) bool {
+			author = strings.ToLower(author)
+			if author == "bot" && len(policy.BotIDs) > 0 && !slices.Contains(policy.BotIDs, scope.post.UserId) {
+				return false
+			}
+			return scope.isAuthor(author)
+		})
+	}
+	return true
+}
+
+// scopeCache remembers the channels, teams and authors of posts the policies were resolved with
+type scopeCache struct {
+	channels   *expiringCache[*model.Channel]
+	teams      *expiringCache[*model.Team]
+	users      *expiringCache[*model.User]
+	teamAdmins *expiringCache[bool]
+}
+
+// newScopeCache creates an empty scope cache
+func newScopeCache() *scopeCache {
+	return &scopeCache{
+		channels:   newExpiringCache[*model.Channel](scopeCacheTTL, scopeCacheMaxEntries),
+		teams:      newExpiringCache[*model.Team](scopeCacheTTL, scopeCacheMaxEntries),
+		users:      newExpiringCache[*model.User](scopeCacheTTL, scopeCacheMaxEntries),
+		teamAdmins: newExpiringCache[bool](scopeCacheTTL, scopeCacheMaxEntries),
+	}
+}
+
+// resetScopeCache forgets every cached channel, team and author
+func (p *Plugin) resetScopeCache() {
+	p.configurationLock.Lock()
+	defer p.configurationLock.Unlock()
+	p.scopeCache = newScopeCache()
+}
+
+// cachedLookup returns the cached value of a key, or looks it up and caches it. Lookups that fail
+// are not cached.
+func cachedLookup[V any](cache *expiringCache[V], key string, lookup func() (V, *model.AppError)) (V, *model.AppError) {
+	if value, ok := cache.get(key); ok {
+		return value, nil
+	}
+	value, appErr := lookup()
+	if appErr == nil {
+		cache.set(key, value)
+	}
+	return value, appErr
+}
+
+// postScope looks up the channel, team and author of a post once, when a policy needs them
+type postScope struct {
+	api   plugin.API
+	cache *scopeCache
+	post  *model.Post
+
+	channelLoaded, teamLoaded, userLoaded, teamAdminLoaded bool
+
+	loadedChannel *model.Channel
+	loadedTeam    *model.Team
+	loadedUser    *model.User
+	teamAdmin     bool
+}
+
+// channel returns the channel of the post, or nil if it cannot be found
+func (s *postScope) channel() *model.Channel {
+	if !s.channelLoaded && s.post.ChannelId != "" {
+		s.channelLoaded = true
+		channel, appErr := cachedLookup(s.cache.channels, s.post.ChannelId, func() (*model.Channel, *model.AppError) {
+			return s.api.GetChannel(s.post.ChannelId)
+		})
+		if appErr != nil {
+			s.api.LogWarn("Failed to get the channel to resolve its policy", "channel_id", s.post.ChannelId, "err", appErr.Error())
+		}
+		s.loadedChannel = channel
+	}
+	return s.loadedChannel
+}
+
+// team returns the team of the channel of the post, or nil for direct and group messages
+func (s *postScope) team() *model.Team {
+	if !s.teamLoaded {
+		s.teamLoaded = true
+		if channel := s.channel(); channel != nil && channel.TeamId != "" {
+			team, appErr := cachedLookup(s.cache.teams, channel.TeamId, func() (*model.Team, *model.AppError) {
+				return s.api.GetTeam(channel.TeamId)
+			})
+			if appErr != nil {
+				s.api.LogWarn("Failed to get the team to resolve its policy", "team_id", channel.TeamId, "err", appErr.Error())
+			}
+			s.loadedTeam = team
+		}
+	}
+	return s.loadedTeam
+}
+
+// user returns the author of the post, or nil if it cannot be found
+func (s *postScope) user() *model.User {
+	if !s.userLoaded {
+		s.userLoaded = true
+		user, appErr := cachedLookup(s.cache.users, s.post.UserId, func() (*model.User, *model.AppError) {
+			return s.api.GetUser(s.post.UserId)
+		})
+		if appErr != nil {
+			s.api.LogWarn("Failed to get the author to resolve its policy", "user_id", s.post.UserId, "err", appErr.Error())
+		}
+		s.loadedUser = user
+	}
+	return s.loadedUser
+}
+
+// isTeamAdmin checks if the author of the post is an admin of the team of its channel
+func (s *postScope) isTeamAdmin() bool {
+	if !s.teamAdminLoaded {
+		s.teamAdminLoaded = true
+		if channel := s.channel(); channel != nil && channel.TeamId != "" {
+			s.teamAdmin, _ = cachedLookup(s.cache.teamAdmins, channel.TeamId+":"+s.post.UserId, func() (bool, *model.AppError) {
+				member, appErr := s.api.GetTeamMember(channel.TeamId, s.post.UserId)
+				if appErr != nil {
+					return false, appErr
+				}
+				return member.SchemeAdmin || model.IsInRole(member.Roles, model.TeamAdminRoleId), nil
+			})
+		}
+	}
+	return s.teamAdmin
+}
+
+// isAuthor checks if the post was written by the given type of author. Mattermost marks the
+// responses of custom slash commands like the posts of incoming webhooks, which are told apart by
+// the display name only incoming webhooks set.
+func (s *postScope) isAuthor(author string) bool {
+	props := s.post.GetProps()
+	switch author {
+	case "system":
+		return s.post.IsSystemMessage()
+	case "webhook":
+		return props[model.PostPropsFromWebhook] == "true" && props[model.PostPropsWebhookDisplayName] != nil
+	case "slash_command":
+		return props[model.PostPropsFromWebhook] == "true" && props[model.PostPropsWebhookDisplayName] == nil
+	case "bot":
+		if _, fromBot := props[model.PostPropsFromBot]; fromBot {
+			return true
+		}
+		user := s.user()
+		return user != nil && user.IsBot
+	case "system_admin":
+		user := s.user()
+		return user != nil && user.IsSystemAdmin()
+	case "guest":
+		user := s.user()
+		return user != nil && user.IsGuest()
+	case "team_admin":
+		return s.isTeamAdmin()
+	}
+	return false
+}
+
+// termPolicy selects the terms that are filtered in a scope. The nil policy is the installation
+// policy, which filters every term of the bad words list.
+type termPolicy struct {
+	// termSets are the compiled terms added by the policies
+	termSets []*termSet
+	// removed are the keys of the terms that are not filtered
+	removed map[string]bool
+	// removedCategories are the categories whose terms are not filtered
+	removedCategories map[string]bool
+	// allowed are the keys of the words that are never censored
+	allowed map[string]bool
+}
+
+// newTermPolicy layers the term lists of the policies. Removed terms stay removed even when
+// another policy adds them.
+func newTermPolicy(policies []scopedPolicy) *termPolicy {
+	policy := &termPolicy{removed: map[string]bool{}, removedCategories: map[string]bool{}, allowed: map[string]bool{}}
+	for _, scoped := range policies {
+		if scoped.terms != nil {
+			policy.termSets = append(policy.termSets, scoped.terms)
+		}
+		for _, entry := range splitWordList(normalizeWordListCommas(scoped.RemoveWords)) {
+			if category, ok := strings.CutPrefix(strings.ToLower(entry), removedCategoryPrefix); ok {
+				policy.removedCategories[strings.TrimSpace(category)] = true
+			} else {
+				policy.removed[termKey(parseTerm(entry).Word)] = true
+			}
+		}
+		for _, entry := range splitWordList(normalizeWordListCommas(scoped.AllowWords)) {
+			policy.allowed[termKey(entry)] = true
+		}
+	}
+	return policy
+}
+
+// filters checks if a term is filtered in the scope
+func (policy *termPolicy) filters(t term) bool {
+	return policy == nil || !(policy.removed[termKey(t.Word)] || policy.removedCategories[t.category()])
+}
+
+// allows checks if a detected word is never censored in the scope
+func (policy *termPolicy) allows(word string) bool {
+	return policy != nil && policy.allowed[termKey(word)]
+}
+
+// detectScoped runs a detection with the terms of the bad words list and with the terms added by
+// the policies of the scope, and applies the term policy to the detections. The detections of the
+// added terms win over the ones they overlap, like longer terms win in a single word list.
+func (p *Plugin) detectScoped(detect func(terms *termSet) []detection, policy *termPolicy) []detection {
+	var detections []detection
+	if policy != nil {
+		for _, terms := range policy.termSets {
+			detections = mergeDetections(detections, applyTermPolicy(detect(terms), terms, policy))
+		}
+	}
+	terms := p.getTerms()
+	return mergeDetections(detections, applyTermPolicy(detect(terms), terms, policy))
+}
+
+// scopeConfiguration returns the configuration of a post, with the action, censor style and terms
+// of the policies that apply to its team, channel and author
+func (p *Plugin) scopeConfiguration(config *configuration, post *model.Post) *configuration {
+	policies := p.getScopedPolicies()
+	if len(policies) == 0 {
+		return config
+	}
+
+	scope := &postScope{api: p.API, cache: p.getScopeCache(), post: post}
+	var matched []scopedPolicy
+	for _, policy := range policies {
+		if policy.matches(scope) {
+			matched = append(matched, policy)
+		}
+	}
+	if len(matched) == 0 {
+		return config
+	}
+
+	scoped := config.Clone()
+	for _, policy := range matched {
+		switch strings.ToLower(policy.Action) {
+		case policyActionCensor:
+			scoped.RejectPosts, scoped.exempt = false, false
+		case policyActionReject:
+			scoped.RejectPosts, scoped.exempt = true, false
+		case policyActionExempt:
+			scoped.exempt = true
+		}
+		if policy.CensorStyle != "" {
+			scoped.CensorStyle = policy.CensorStyle
+		}
+	}
+	scoped.termPolicy = newTermPolicy(matched)
+	return scoped
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+)
+
+func TestScopedPolicies(t *testing.T) {
+	policies := `[
+		{"team": "support", "action": "reject"},
+		{"team": "engineering", "channel": "random", "add_words": "heck", "censor_style": "placeholder"},
+		{"team": "Engineering", "remove_words": "category=mild"},
+		{"team": "medical-id", "remove_words": "penis", "allow_words": "Dick"}
+	]`
+
+	newPlugin := func(t *testing.T) (*Plugin, *plugintest.API) {
+		p, api := newTestPluginWithAPI(t, &configuration{
+			CensorCharacter: "*",
+			BadWordsList:    "fuck,dick,penis#category=anatomy,damn#category=mild",
+			ScopedPolicies:  policies,
+			WarningMessage:  "Not allowed: %s",
+		})
+
+		channels := []*model.Channel{
+			{Id: "support-town", Name: "town-square", TeamId: "support-id"},
+			{Id: "eng-town", Name: "town-square", TeamId: "eng-id"},
+			{Id: "eng-random", Name: "random", TeamId: "eng-id"},
+			{Id: "medical-town", Name: "town-square", TeamId: "medical-id"},
+			{Id: "other-random", Name: "random", TeamId: "other-id"},
+		}
+		for _, channel := range channels {
+			api.On("GetChannel", channel.Id).Return(channel, nil)
+		}
+		api.On("GetChannel", "missing").Return(nil, model.NewAppError("GetChannel", "not_found", nil, "", 404))
+		for id, name := range map[string]string{"support-id": "support", "eng-id": "engineering", "medical-id": "medical", "other-id": "other"} {
+			api.On("GetTeam", id).Return(&model.Team{Id: id, Name: name}, nil)
+		}
+		api.On("LogWarn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+		return p, api
+	}
+
+	testCases := []struct {
+		name      string
+		channelID string
+		message   string
+		expected  string
+		rejected  bool
+	}{
+		{"no policy", "other-random", "fuck damn heck penis", "**** **** heck *****", false},
+		{"team action", "support-town", "fuck this", "", true},
+		{"team removes a category", "eng-town", "fuck damn heck", "**** damn heck", false},
+		{"channel adds words and overrides the style", "eng-random", "fuck damn heck", "[censored] damn [censored]", false},
+		{"team by ID removes and allows words", "medical-town", "penis Dick fuck", "penis Dick ****", false},
+		{"channel not found", "missing", "fuck damn heck", "**** **** heck", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, api := newPlugin(t)
+			if tc.rejected {
+				api.On("SendEphemeralPost", "user-id", mock.Anything).Return(&model.Post{}).Once()
+			}
+
+			post := &model.Post{UserId: "user-id", ChannelId: tc.channelID, Message: tc.message}
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, post)
+
+			t.Logf("Input: %s", tc.message)
+			if tc.rejected {
+				assert.Nil(t, rpost)
+				assert.NotEmpty(t, s)
+				api.AssertCalled(t, "SendEphemeralPost", "user-id", mock.Anything)
+				return
+			}
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expected)
+			assert.Empty(t, s)
+			assert.Equal(t, tc.expected, rpost.Message)
+		})
+	}
+
+	t.Run("scoped words are not filtered outside their scope", func(t *testing.T) {
+		p, _ := newPlugin(t)
+		assert.Empty(t, p.detectFileName("heck.txt", nil))
+		assert.NotEmpty(t, p.detectFileName("fuck.txt", nil))
+	})
+
+	t.Run("added phrases do not take matches outside their scope", func(t *testing.T) {
+		p, api := newTestPluginWithAPI(t, &configuration{
+			CensorCharacter: "*",
+			BadWordsList:    "hell,fuck",
+			ScopedPolicies:  `[{"channel": "scoped-id", "add_words": "bloody hell"}]`,
+		})
+		api.On("GetChannel", "scoped-id").Return(&model.Channel{Id: "scoped-id", Name: "scoped"}, nil)
+		api.On("GetChannel", "other-id").Return(&model.Channel{Id: "other-id", Name: "other"}, nil)
+
+		for channelID, expected := range map[string]string{
+			"other-id":  "bloody **** **** off",
+			"scoped-id": "*********** **** off",
+		} {
+			post := &model.Post{UserId: "user-id", ChannelId: channelID, Message: "bloody hell fuck off"}
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, post)
+			assert.Empty(t, s)
+			assert.Equal(t, expected, rpost.Message, channelID)
+		}
+	})
+
+	t.Run("channels selected by ID are not looked up", func(t *testing.T) {
+		p, api := newTestPluginWithAPI(t, &configuration{
+			CensorCharacter: "*",
+			BadWordsList:    "fuck",
+			ScopedPolicies:  `[{"channel": "scoped-id", "add_words": "heck"}]`,
+		})
+
+		rpost, _ := p.MessageWillBePosted(&plugin.Context{}, &model.Post{UserId: "user-id", ChannelId: "scoped-id", Message: "heck"})
+		assert.Equal(t, "****", rpost.Message)
+		api.AssertNotCalled(t, "GetChannel", mock.Anything)
+	})
+
+	t.Run("channels and teams are cached", func(t *testing.T) {
+		p, api := newPlugin(t)
+		p.resetScopeCache()
+
+		for range 2 {
+			rpost, _ := p.MessageWillBePosted(&plugin.Context{}, &model.Post{UserId: "user-id", ChannelId: "eng-random", Message: "heck"})
+			assert.Equal(t, "[censored]", rpost.Message)
+		}
+		api.AssertNumberOfCalls(t, "GetChannel", 1)
+		api.AssertNumberOfCalls(t, "GetTeam", 1)
+
+		p.resetScopeCache()
+		p.MessageWillBePosted(&plugin.Context{}, &model.Post{UserId: "user-id", ChannelId: "eng-random", Message: "heck"})
+		api.AssertNumberOfCalls(t, "GetChannel", 2)
+	})
+
+	t.Run("file uploads", func(t *testing.T) {
+		p, _ := newPlugin(t)
+		p.configuration.FilterFileUploads = true
+
+		upload := func(channelID, name string) string {
+			info := &model.FileInfo{Name: name, CreatorId: "user-id", ChannelId: channelID}
+			_, reason := p.FileWillBeUploaded(&plugin.Context{}, info, bytes.NewReader(nil), &bytes.Buffer{})
+			return reason
+		}
+
+		assert.NotEmpty(t, upload("eng-random", "heck.txt"), "words added by the channel")
+		assert.Empty(t, upload("other-random", "heck.txt"))
+		assert.Empty(t, upload("eng-town", "damn.txt"), "category removed by the team")
+		assert.NotEmpty(t, upload("other-random", "damn.txt"))
+	})
+
+	t.Run("invalid policies", func(t *testing.T) {
+		for _, setting := range []string{
+			`{"team": "support"}`,
+			`[{"action": "reject"}]`,
+			`[{"team": "support", "action": "delete"}]`,
+			`[{"team": "support", "censor_style": "blur"}]`,
+		} {
+			p := &Plugin{configuration: &configuration{ScopedPolicies: setting}}
+			assert.Error(t, p.compileWordRegexes("fuck"), setting)
+		}
+	})
+}
+
+func TestPolicyProfiles(t *testing.T) {
+	policies := `[
+		{"authors": ["guest"], "action": "reject"},
+		{"channel_types": ["direct", "group"], "action": "exempt"},
+		{"authors": ["bot"], "bot_ids": ["trusted-bot"], "action": "exempt"},
+		{"authors": ["webhook"], "censor_style": "placeholder"},
+		{"authors": ["slash_command"], "censor_style": "first-letter"},
+		{"authors": ["system"], "action": "exempt"},
+		{"team": "engineering", "authors": ["team_admin", "system_admin"], "remove_words": "damn"}
+	]`
+
+	newPlugin := func(t *testing.T) (*Plugin, *plugintest.API) {
+		p, api := newTestPluginWithAPI(t, &configuration{
+			CensorCharacter: "*",
+			BadWordsList:    "fuck,damn",
+			ScopedPolicies:  policies,
+			WarningMessage:  "Not allowed: %s",
+		})
+
+		api.On("GetChannel", "town-square").Return(&model.Channel{Id: "town-square", Name: "town-square", TeamId: "eng-id", Type: model.ChannelTypeOpen}, nil)
+		api.On("GetChannel", "dm").Return(&model.Channel{Id: "dm", Type: model.ChannelTypeDirect}, nil)
+		api.On("GetTeam", "eng-id").Return(&model.Team{Id: "eng-id", Name: "engineering"}, nil)
+		for _, user := range []*model.User{
+			{Id: "member", Roles: model.SystemUserRoleId},
+			{Id: "guest", Roles: model.SystemGuestRoleId},
+			{Id: "admin", Roles: model.SystemUserRoleId + " " + model.SystemAdminRoleId},
+			{Id: "lead", Roles: model.SystemUserRoleId},
+			{Id: "trusted-bot", IsBot: true},
+			{Id: "other-bot", IsBot: true},
+		} {
+			api.On("GetUser", user.Id).Return(user, nil)
+			api.On("GetTeamMember", "eng-id", user.Id).Return(&model.TeamMember{TeamId: "eng-id", UserId: user.Id, SchemeAdmin: user.Id == "lead"}, nil)
+		}
+		return p, api
+	}
+
+	testCases := []struct {
+		name      string
+		userID    string
+		channelID string
+		postType  string
+		props     model.StringInterface
+		expected  string
+		rejected  bool
+	}{
+		{"member in a public channel", "member", "town-square", "", nil, "**** ****", false},
+		{"member in a direct message", "member", "dm", "", nil, "fuck damn", false},
+		{"guest in a direct message", "guest", "dm", "", nil, "", true},
+		{"allowlisted bot", "trusted-bot", "town-square", "", nil, "fuck damn", false},
+		{"other bot", "other-bot", "town-square", "", nil, "**** ****", false},
+		{"incoming webhook", "member", "town-square", "", model.StringInterface{model.PostPropsFromWebhook: "true", model.PostPropsWebhookDisplayName: "Alerts"}, "[censored] [censored]", false},
+		{"slash command", "member", "town-square", "", model.StringInterface{model.PostPropsFromWebhook: "true"}, "f*** d***", false},
+		{"system message", "member", "town-square", model.PostTypeJoinChannel, nil, "fuck damn", false},
+		{"team admin", "lead", "town-square", "", nil, "**** damn", false},
+		{"system admin", "admin", "town-square", "", nil, "**** damn", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, api := newPlugin(t)
+			if tc.rejected {
+				api.On("SendEphemeralPost", tc.userID, mock.Anything).Return(&model.Post{}).Once()
+			}
+
+			post := &model.Post{UserId: tc.userID, ChannelId: tc.channelID, Type: tc.postType, Message: "fuck damn"}
+			post.SetProps(tc.props)
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, post)
+
+			if tc.rejected {
+				assert.Nil(t, rpost)
+				assert.NotEmpty(t, s)
+				api.AssertCalled(t, "SendEphemeralPost", tc.userID, mock.Anything)
+				return
+			}
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expected)
+			assert.Empty(t, s)
+			assert.Equal(t, tc.expected, rpost.Message)
+		})
+	}
+
+	t.Run("authors are only looked up when a policy needs them", func(t *testing.T) {
+		p, api := newPlugin(t)
+		post := &model.Post{UserId: "member", ChannelId: "dm", Message: "fuck"}
+		post.AddProp(model.PostPropsFromWebhook, "true")
+
+		p.MessageWillBePosted(&plugin.Context{}, post)
+
+		api.AssertNotCalled(t, "GetTeamMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("authors are cached", func(t *testing.T) {
+		p, api := newPlugin(t)
+		p.resetScopeCache()
+
+		for range 2 {
+			rpost, _ := p.MessageWillBePosted(&plugin.Context{}, &model.Post{UserId: "lead", ChannelId: "town-square", Message: "fuck damn"})
+			assert.Equal(t, "**** damn", rpost.Message)
+		}
+		api.AssertNumberOfCalls(t, "GetUser", 1)
+		api.AssertNumberOfCalls(t, "GetTeamMember", 1)
+	})
+
+	t.Run("invalid selectors", func(t *testing.T) {
+		for _, setting := range []string{
+			`[{"channel_types": ["secret"]}]`,
+			`[{"authors": ["moderator"]}]`,
+		} {
+			p := &Plugin{configuration: &configuration{ScopedPolicies: setting}}
+			assert.Error(t, p.compileWordRegexes("fuck"), setting)
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+const (
+	// profileActionReset clears the profile fields that contain bad words, which are otherwise
+	// only flagged in the report
+	profileActionReset = "reset"
+
+	// profileFieldCustomStatus is the custom status, which is reset separately from the profile
+	profileFieldCustomStatus = "custom_status"
+
+	// profileReportKeyPrefix prefixes the KV store keys that record the hash of the findings last
+	// reported for a user
+	profileReportKeyPrefix = "profile_report_"
+)
+
+// profileField is a user-visible text of a user profile
+type profileField struct {
+	Path string
+	get  func(user *model.User) string
+	// reset clears the field, or is nil for fields that cannot be changed, such as the username
+	reset func(user *model.User)
+}
+
+// profileFields are the texts of user profiles that are checked
+var profileFields = []profileField{
+	{"username", func(u *model.User) string { return u.Username }, nil},
+	{"nickname", func(u *model.User) string { return u.Nickname }, func(u *model.User) { u.Nickname = "" }},
+	{"first_name", func(u *model.User) string { return u.FirstName }, func(u *model.User) { u.FirstName = "" }},
+	{"last_name", func(u *model.User) string { return u.LastName }, func(u *model.User) { u.LastName = "" }},
+	{"position", func(u *model.User) string { return u.Position }, func(u *model.User) { u.Position = "" }},
+	{profileFieldCustomStatus, func(u *model.User) string {
+		if status := u.GetCustomStatus(); status != nil {
+			return status.Text
+		}
+		return ""
+	}, func(u *model.User) { u.ClearCustomStatus() }},
+}
+
+// profileFinding is a profile field that contains bad words
+type profileFinding struct {
+	UserID   string
+	Username string
+	Field    string
+	Value    string
+	Words    []string
+	// Reset marks the fields that were cleared, as opposed to only flagged for review
+	Reset bool
+}
+
+// UserHasBeenCreated checks the profile of new users
+func (p *Plugin) UserHasBeenCreated(_ *plugin.Context, user *model.User) {
+	if !p.getConfiguration().FilterProfiles {
+		return
+	}
+	p.reportProfileFindings(p.unreportedProfileFindings(user.Id, p.enforceProfilePolicy(user)))
+}
+
+// sweepProfiles checks the profiles of every active user and reports the findings to the moderators
+func (p *Plugin) sweepProfiles() {
+	if !p.getConfiguration().FilterProfiles {
+		return
+	}
+
+	var findings []profileFinding
+	total := 0
+	for page := 0; ; page++ {
+		users, appErr := p.API.GetUsers(&model.UserGetOptions{Page: page, PerPage: sweepPageSize, Active: true})
+		if appErr != nil {
+			p.API.LogWarn("Failed to get the users to sweep", "page", page, "err", appErr.Error())
+			break
+		}
+		for _, user := range users {
+			userFindings := p.enforceProfilePolicy(user)
+			total += len(userFindings)
+			findings = append(findings, p.unreportedProfileFindings(user.Id, userFindings)...)
+		}
+		if len(users) < sweepPageSize {
+			break
+		}
+	}
+
+	p.API.LogDebug("Profile sweep finished", "findings", total, "unreported", len(findings))
+	p.reportProfileFindings(findings)
+}
+
+// enforceProfilePolicy finds the profile fields of a user that contain bad words, and resets them
+// if configured. Bots are left alone.
+func (p *Plugin) enforceProfilePolicy(user *model.User) []profileFinding {
+	configuration := p.getConfiguration()
+	if user == nil || user.IsBot {
+		return nil
+	}
+
+	terms := p.getTerms()
+	var findings []profileFinding
+	updateUser, removeCustomStatus := false, false
+	for _, field := range profileFields {
+		value := field.get(user)
+		detections := applyTermOptions(p.detectPlainProfanityWords(value, terms), terms)
+		if len(detections) == 0 {
+			continue
+		}
+
+		finding := profileFinding{UserID: user.Id, Username: user.Username, Field: field.Path, Value: value, Words: detectedWords(detections)}
+		if configuration.ProfileAction == profileActionReset && field.reset != nil {
+			field.reset(user)
+			finding.Reset = true
+			if field.Path == profileFieldCustomStatus {
+				removeCustomStatus = true
+			} else {
+				updateUser = true
+			}
+		}
+		findings = append(findings, finding)
+	}
+
+	if updateUser {
+		if _, appErr := p.API.UpdateUser(user); appErr != nil {
+			p.API.LogWarn("Failed to reset a profile containing profanity", "user_id", user.Id, "err", appErr.Error())
+			return unresetProfileFindings(findings)
+		}
+	}
+	if removeCustomStatus {
+		if appErr := p.API.RemoveUserCustomStatus(user.Id); appErr != nil {
+			p.API.LogWarn("Failed to reset a custom status containing profanity", "user_id", user.Id, "err", appErr.Error())
+		}
+	}
+
+	var reset []string
+	for _, finding := range findings {
+		if finding.Reset {
+			reset = append(reset, strings.ReplaceAll(finding.Field, "_", " "))
+		}
+	}
+	if len(reset) > 0 {
+		p.notifyUser(user.Id, fmt.Sprintf("Your %s contained profanity and was reset.", strings.Join(reset, ", ")))
+	}
+
+	return findings
+}
+
+// unresetProfileFindings marks the findings as flagged only, when resetting the profile failed
+func unresetProfileFindings(findings []profileFinding) []profileFinding {
+	for i := range findings {
+		if findings[i].Field != profileFieldCustomStatus {
+			findings[i].Reset = false
+		}
+	}
+	return findings
+}
+
+// unreportedProfileFindings returns the findings of a user unless the same findings were already
+// reported, and records a hash of the findings in the KV store so that the next sweep only reports
+// them again once they change. The record is deleted once the profile is clean.
+func (p *Plugin) unreportedProfileFindings(userID string, findings []profileFinding) []profileFinding {
+	key := profileReportKeyPrefix + userID
+	reported, appErr := p.API.KVGet(key)
+	if appErr != nil {
+		p.API.LogWarn("Failed to get the profile findings reported before", "user_id", userID, "err", appErr.Error())
+		return findings
+	}
+
+	if len(findings) == 0 {
+		if reported != nil {
+			if appErr := p.API.KVDelete(key); appErr != nil {
+				p.API.LogWarn("Failed to delete the profile findings reported before", "user_id", userID, "err", appErr.Error())
+			}
+		}
+		return nil
+	}
+
+	hash := hashProfileFindings(findings)
+	if string(reported) == hash {
+		return nil
+	}
+	if appErr := p.API.KVSet(key, []byte(hash)); appErr != nil {
+		p.API.LogWarn("Failed to record the reported profile findings", "user_id", userID, "err", appErr.Error())
+	}
+	return findings
+}
+
+// hashProfileFindings hashes the fields and values of the findings, which identify a report
+func hashProfileFindings(findings []profileFinding) string {
+	hash := sha256.New()
+	for _, finding := range findings {
+		fmt.Fprintf(hash, "%s\x00%s\x00%t\n", finding.Field, finding.Value, finding.Reset)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// reportProfileFindings sends the moderators a table of the profile fields containing bad words,
+// so that they can review the fields that were flagged, such as usernames
+func (p *Plugin) reportProfileFindings(findings []profileFinding) {
+	if len(findings) == 0 {
+		return
+	}
+	for _, finding := range findings {
+		p.API.LogDebug("Profanity detected in profile", "user_id", finding.UserID, "path", finding.Field, "words", strings.Join(finding.Words, ", "), "reset", finding.Reset)
+	}
+	p.notifyModeratorsReport(profileReportHeader, formatProfileReportRows(findings))
+}
+
+// profileReportHeader is the header of the table of profile findings
+const profileReportHeader = "#### Profiles containing profanity\n\n" +
+	"| User | Field | Value | Words | Action |\n" +
+	"|:-----|:------|:------|:------|:-------|\n"
+
+// formatProfileReportRows formats the findings as the rows of a markdown table
+func formatProfileReportRows(findings []profileFinding) []string {
+	cell := strings.NewReplacer("|", `\|`, "\n", " ")
+
+	rows := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		action := "Flagged for review"
+		if finding.Reset {
+			action = "Reset"
+		}
+		rows = append(rows, fmt.Sprintf("| @%s | %s | %s | %s | %s |\n", finding.Username, strings.ReplaceAll(finding.Field, "_", " "),
+			cell.Replace(finding.Value), cell.Replace(strings.Join(finding.Words, ", ")), action))
+	}
+	return rows
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+)
+
+func TestProfilePolicy(t *testing.T) {
+	newPlugin := func(t *testing.T, config *configuration) (*Plugin, *plugintest.API) {
+		config.FilterProfiles = true
+		config.BadWordsList = "fuck,shit,dick"
+		p, api := newTestPluginWithAPI(t, config)
+		p.botID = "bot-id"
+		return p, api
+	}
+
+	newUser := func() *model.User {
+		user := &model.User{Id: "user-id", Username: "dick.smith", Nickname: "Shithead", FirstName: "Dick", Position: "Engineer"}
+		user.SetCustomStatus(&model.CustomStatus{Emoji: "smile", Text: "fuck mondays"})
+		return user
+	}
+
+	t.Run("findings are flagged", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{})
+		user := newUser()
+
+		findings := p.enforceProfilePolicy(user)
+
+		var fields []string
+		for _, finding := range findings {
+			fields = append(fields, finding.Field)
+			assert.False(t, finding.Reset)
+		}
+		assert.Equal(t, []string{"username", "first_name", "custom_status"}, fields)
+		assert.Equal(t, "Shithead", user.Nickname, "only plain words are matched")
+		assert.Equal(t, "Dick", user.FirstName)
+		api.AssertNotCalled(t, "UpdateUser", mock.Anything)
+	})
+
+	t.Run("findings are reset", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{ProfileAction: "reset"})
+		user := newUser()
+
+		api.On("UpdateUser", mock.Anything).Return(user, nil).Once()
+		api.On("RemoveUserCustomStatus", "user-id").Return(nil).Once()
+		api.On("GetDirectChannel", "bot-id", "user-id").Return(&model.Channel{Id: "dm"}, nil).Once()
+		api.On("CreatePost", &model.Post{UserId: "bot-id", ChannelId: "dm", Message: "Your first name, custom status contained profanity and was reset."}).Return(&model.Post{}, nil).Once()
+
+		findings := p.enforceProfilePolicy(user)
+
+		require.Len(t, findings, 3)
+		assert.False(t, findings[0].Reset, "usernames cannot be reset")
+		assert.True(t, findings[1].Reset)
+		assert.True(t, findings[2].Reset)
+		assert.Equal(t, "", user.FirstName)
+		assert.Equal(t, "dick.smith", user.Username)
+		api.AssertExpectations(t)
+	})
+
+	t.Run("bots are skipped", func(t *testing.T) {
+		p, _ := newPlugin(t, &configuration{})
+		user := newUser()
+		user.IsBot = true
+
+		assert.Empty(t, p.enforceProfilePolicy(user))
+	})
+
+	t.Run("new users are reported to moderators", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{ModeratorUsernames: "mod"})
+		user := &model.User{Id: "user-id", Username: "alice", Position: "Shit | Manager"}
+
+		api.On("KVGet", profileReportKeyPrefix+"user-id").Return(nil, nil)
+		api.On("KVSet", profileReportKeyPrefix+"user-id", mock.Anything).Return(nil).Once()
+		api.On("GetUserByUsername", "mod").Return(&model.User{Id: "mod-id"}, nil)
+		api.On("GetDirectChannel", "bot-id", "mod-id").Return(&model.Channel{Id: "dm"}, nil)
+		api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return post.ChannelId == "dm" && assert.Contains(t, post.Message, `| @alice | position | Shit \| Manager | Shit | Flagged for review |`)
+		})).Return(&model.Post{}, nil).Once()
+
+		p.UserHasBeenCreated(&plugin.Context{}, user)
+
+		api.AssertExpectations(t)
+	})
+
+	t.Run("sweep pages through active users", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{})
+
+		firstPage := make([]*model.User, sweepPageSize)
+		for i := range firstPage {
+			firstPage[i] = &model.User{Id: model.NewId(), Username: "user"}
+		}
+		firstPage[10].Nickname = "fuck"
+
+		api.On("GetUsers", &model.UserGetOptions{Page: 0, PerPage: sweepPageSize, Active: true}).Return(firstPage, nil).Once()
+		api.On("GetUsers", &model.UserGetOptions{Page: 1, PerPage: sweepPageSize, Active: true}).Return([]*model.User{{Id: "last", Username: "shit"}}, nil).Once()
+		api.On("KVGet", mock.Anything).Return(nil, nil)
+		api.On("KVSet", mock.Anything, mock.Anything).Return(nil).Twice()
+
+		p.sweepProfiles()
+
+		api.AssertExpectations(t)
+		api.AssertCalled(t, "LogDebug", "Profile sweep finished", "findings", 2, "unreported", 2)
+	})
+
+	t.Run("sweep only reports new and changed findings", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{ModeratorUsernames: "mod"})
+
+		reported := &model.User{Id: "reported", Username: "reported", Position: "shit"}
+		changed := &model.User{Id: "changed", Username: "changed", Position: "fuck"}
+		clean := &model.User{Id: "clean", Username: "clean"}
+		unchanged := hashProfileFindings(p.enforceProfilePolicy(reported))
+
+		api.On("GetUsers", mock.Anything).Return([]*model.User{reported, changed, clean}, nil).Once()
+		api.On("KVGet", profileReportKeyPrefix+"reported").Return([]byte(unchanged), nil)
+		api.On("KVGet", profileReportKeyPrefix+"changed").Return([]byte("outdated"), nil)
+		api.On("KVGet", profileReportKeyPrefix+"clean").Return([]byte("outdated"), nil)
+		api.On("KVSet", profileReportKeyPrefix+"changed", mock.Anything).Return(nil).Once()
+		api.On("KVDelete", profileReportKeyPrefix+"clean").Return(nil).Once()
+		api.On("GetUserByUsername", "mod").Return(&model.User{Id: "mod-id"}, nil)
+		api.On("GetDirectChannel", "bot-id", "mod-id").Return(&model.Channel{Id: "dm"}, nil)
+		api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return assert.Contains(t, post.Message, "@changed") && assert.NotContains(t, post.Message, "@reported")
+		})).Return(&model.Post{}, nil).Once()
+
+		p.sweepProfiles()
+
+		api.AssertExpectations(t)
+	})
+}
+
+func TestSplitReport(t *testing.T) {
+	header := "| a |\n"
+	rows := []string{"| 1 |\n", "| 2 |\n", "| 3 |\n"}
+
+	assert.Equal(t, []string{header + rows[0] + rows[1] + rows[2]}, splitReport(header, rows, 100))
+	assert.Equal(t, []string{header + rows[0] + rows[1], header + rows[2]}, splitReport(header, rows, 18))
+	assert.Equal(t, []string{header + rows[0], header + rows[1], header + rows[2]}, splitReport(header, rows, 1), "rows are never split")
+	assert.Empty(t, splitReport(header, nil, 100))
+}
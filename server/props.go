@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// visibleText is a user-visible text of a post or channel, located by its path, e.g. "message",
+// "attachments[0].fields[1].value" or "header"
+type visibleText struct {
+	Path string
+	Text string
+	// Markdown marks the texts Mattermost renders as markdown, as opposed to plain text
+	Markdown bool
+	// set replaces the text in the post
+	set func(text string)
+}
+
+// postTexts collects the message of a post and the user-visible texts of its props: the
+// Slack-style attachments with their interactive buttons and menus, and the card
+func postTexts(post *model.Post) []visibleText {
+	texts := []visibleText{{
+		Path:     "message",
+		Text:     post.Message,
+		Markdown: true,
+		set:      func(text string) { post.Message = text },
+	}}
+
+	attachments := post.Attachments()
+	add := func(path string, field *string, markdown bool) {
+		if *field == "" {
+			return
+		}
+		texts = append(texts, visibleText{Path: path, Text: *field, Markdown: markdown, set: func(text string) {
+			*field = text
+			post.AddProp("attachments", attachments)
+		}})
+	}
+
+	for i, attachment := range attachments {
+		if attachment == nil {
+			continue
+		}
+		path := fmt.Sprintf("attachments[%d]", i)
+		add(path+".fallback", &attachment.Fallback, false)
+		add(path+".pretext", &attachment.Pretext, true)
+		add(path+".author_name", &attachment.AuthorName, false)
+		add(path+".title", &attachment.Title, true)
+		add(path+".text", &attachment.Text, true)
+		for j, field := range attachment.Fields {
+			if field == nil {
+				continue
+			}
+			fieldPath := fmt.Sprintf("%s.fields[%d]", path, j)
+			add(fieldPath+".title", &field.Title, false)
+			if value, ok := field.Value.(string); ok && value != "" {
+				texts = append(texts, visibleText{Path: fieldPath + ".value", Text: value, Markdown: true, set: func(text string) {
+					field.Value = text
+					post.AddProp("attachments", attachments)
+				}})
+			}
+		}
+		add(path+".footer", &attachment.Footer, false)
+		for j, action := range attachment.Actions {
+			if action == nil {
+				continue
+			}
+			actionPath := fmt.Sprintf("%s.actions[%d]", path, j)
+			add(actionPath+".name", &action.Name, false)
+			for k, option := range action.Options {
+				if option != nil {
+					add(fmt.Sprintf("%s.options[%d].text", actionPath, k), &option.Text, false)
+				}
+			}
+		}
+	}
+
+	if card, ok := post.GetProp("card").(string); ok && card != "" {
+		texts = append(texts, visibleText{Path: "card", Text: card, Markdown: true, set: func(text string) {
+			post.AddProp("card", text)
+		}})
+	}
+
+	return texts
+}
+
+// detectText detects the bad words of a text shown in a channel, leaving out the mentions, channel
+// links, emoji and hashtags that refer to something that exists
+func (p *Plugin) detectText(channelID string, text visibleText, config *configuration) []detection {
+	detections := p.detectScoped(func(terms *termSet) []detection {
+		if text.Markdown {
+			// Use hybrid detection system that separates ASCII and non-ASCII word detection for better
+			// multilingual support, on the regions of the markdown text that are configured to be scanned
+			return p.detectMarkdownProfanityWords(text.Text, terms)
+		}
+		return p.detectPlainProfanityWords(text.Text, terms)
+	}, config.termPolicy)
+
+	if len(detections) > 0 {
+		detections = removeExcludedDetections(detections, p.findExclusions(channelID, text.Text, config))
+	}
+	return detections
+}
+
+// detectPlainProfanityWords detects the bad words of the term set in a text that is not rendered as
+// markdown, so that it is censored verbatim
+func (p *Plugin) detectPlainProfanityWords(text string, terms *termSet) []detection {
+	detections := p.detectAllProfanityWords(text, terms)
+	for i := range detections {
+		detections[i].Verbatim = true
+	}
+	return detections
+}
+
+// logCensoredPaths reports the texts of a post that were censored
+func (p *Plugin) logCensoredPaths(post *model.Post, paths []string) {
+	if p.API == nil || len(paths) == 0 {
+		return
+	}
+	p.API.LogDebug("Post censored", "post_id", post.Id, "user_id", post.UserId, "paths", strings.Join(paths, ", "))
+}
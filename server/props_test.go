@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+)
+
+func TestPostProps(t *testing.T) {
+	newPlugin := func(t *testing.T, config *configuration) (*Plugin, *plugintest.API) {
+		config.CensorCharacter = "*"
+		config.BadWordsList = "fuck,shit"
+		return newTestPluginWithAPI(t, config)
+	}
+
+	newAttachments := func() []*model.SlackAttachment {
+		return []*model.SlackAttachment{{
+			Fallback:   "shit happened",
+			Pretext:    "**shit** happened",
+			AuthorName: "Fuck Bot",
+			Title:      "Build failed",
+			Text:       "What the fuck",
+			Fields: []*model.SlackAttachmentField{
+				{Title: "shit", Value: "fuck"},
+				{Title: "Count", Value: 3},
+			},
+			Footer: "fuck monitoring",
+			Actions: []*model.PostAction{{
+				Type:    "select",
+				Name:    "Pick shit",
+				Options: []*model.PostActionOptions{{Text: "fuck it", Value: "fuck"}},
+			}},
+		}}
+	}
+
+	t.Run("attachments and card are censored", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{CensorEscapeMarkdown: true})
+
+		post := &model.Post{Message: "clean"}
+		post.AddProp("attachments", newAttachments())
+		post.AddProp("card", "shit card")
+
+		rpost, s := p.MessageWillBePosted(&plugin.Context{}, post)
+		assert.Empty(t, s)
+
+		attachment := rpost.Attachments()[0]
+		assert.Equal(t, "clean", rpost.Message)
+		assert.Equal(t, "**** happened", attachment.Fallback)
+		assert.Equal(t, `**\*\*\*\*** happened`, attachment.Pretext)
+		assert.Equal(t, "**** Bot", attachment.AuthorName)
+		assert.Equal(t, "Build failed", attachment.Title)
+		assert.Equal(t, `What the \*\*\*\*`, attachment.Text)
+		assert.Equal(t, "****", attachment.Fields[0].Title)
+		assert.Equal(t, `\*\*\*\*`, attachment.Fields[0].Value)
+		assert.Equal(t, 3, attachment.Fields[1].Value)
+		assert.Equal(t, "**** monitoring", attachment.Footer)
+		assert.Equal(t, "Pick ****", attachment.Actions[0].Name)
+		assert.Equal(t, "**** it", attachment.Actions[0].Options[0].Text)
+		assert.Equal(t, "fuck", attachment.Actions[0].Options[0].Value, "values are not user-visible")
+		assert.Equal(t, `\*\*\*\* card`, rpost.GetProp("card"))
+
+		api.AssertCalled(t, "LogDebug", "Post censored", "post_id", mock.Anything, "user_id", mock.Anything, "paths",
+			"attachments[0].fallback, attachments[0].pretext, attachments[0].author_name, attachments[0].text, "+
+				"attachments[0].fields[0].title, attachments[0].fields[0].value, attachments[0].footer, "+
+				"attachments[0].actions[0].name, attachments[0].actions[0].options[0].text, card")
+	})
+
+	t.Run("attachments decoded from JSON are censored", func(t *testing.T) {
+		p, _ := newPlugin(t, &configuration{})
+
+		var decoded []any
+		encoded, err := json.Marshal([]*model.SlackAttachment{{Text: "fuck this"}})
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+
+		post := &model.Post{Message: "fuck"}
+		post.AddProp("attachments", decoded)
+
+		rpost, s := p.MessageWillBePosted(&plugin.Context{}, post)
+		assert.Empty(t, s)
+		assert.Equal(t, "****", rpost.Message)
+		assert.Equal(t, "**** this", rpost.Attachments()[0].Text)
+	})
+
+	t.Run("profanity in attachments rejects the post", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{RejectPosts: true, WarningMessage: "not allowed: %s"})
+		api.On("SendEphemeralPost", "user-id", mock.Anything).Return(nil)
+
+		post := &model.Post{UserId: "user-id", Message: "clean"}
+		post.AddProp("attachments", newAttachments())
+
+		rpost, s := p.MessageWillBePosted(&plugin.Context{}, post)
+		assert.Nil(t, rpost)
+		assert.Contains(t, s, "Profane word not allowed")
+	})
+
+	t.Run("posts without profanity are unchanged", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{})
+
+		post := &model.Post{Message: "clean"}
+		post.AddProp("attachments", []*model.SlackAttachment{{Text: "all good"}})
+
+		rpost, s := p.MessageWillBePosted(&plugin.Context{}, post)
+		assert.Empty(t, s)
+		assert.Equal(t, "all good", rpost.Attachments()[0].Text)
+		api.AssertNotCalled(t, "LogDebug", "Post censored", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+// detectEmojiName detects the bad words of an emoji name: emoji terms matching its shortcode, such
+// as ":middle_finger:", and, for custom emoji, the words of the name, e.g. "fuck" in "fuck_this".
+// The names of system emoji are not chosen by users and are not matched as words.
+func (p *Plugin) detectEmojiName(name string) []detection {
+	terms := p.getTerms()
+	detections := p.detectPlainProfanityWords(":"+name+":", terms)
+	detections = slices.DeleteFunc(detections, func(d detection) bool { return d.Strategy != "emoji" })
+
+	if _, system := model.SystemEmojis[name]; !system {
+		detections = append(detections, p.detectPlainProfanityWords(separatorsToSpaces(name, "_-+"), terms)...)
+	}
+	return applyTermOptions(detections, terms)
+}
+
+// ReactionHasBeenAdded removes the reactions whose emoji name contains bad words, and notifies
+// the user who reacted
+func (p *Plugin) ReactionHasBeenAdded(_ *plugin.Context, reaction *model.Reaction) {
+	if !p.getConfiguration().FilterReactions || reaction == nil {
+		return
+	}
+
+	detections := p.detectEmojiName(reaction.EmojiName)
+	if len(detections) == 0 {
+		return
+	}
+
+	for _, d := range detections {
+		p.API.LogDebug("Profanity detected in reaction", "post_id", reaction.PostId, "user_id", reaction.UserId, "emoji_name", reaction.EmojiName, "word", d.Word, "strategy", d.Strategy)
+	}
+	if appErr := p.API.RemoveReaction(reaction); appErr != nil {
+		p.API.LogWarn("Failed to remove a reaction containing profanity", "post_id", reaction.PostId, "emoji_name", reaction.EmojiName, "err", appErr.Error())
+		return
+	}
+	p.notifyUser(reaction.UserId, fmt.Sprintf("Your reaction :%s: was removed because its name contains profanity.", reaction.EmojiName))
+}
+
+// sweepCustomEmoji lists the custom emoji whose names contain bad words to the moderators, so that
+// they can delete them
+func (p *Plugin) sweepCustomEmoji() {
+	if !p.getConfiguration().FilterReactions {
+		return
+	}
+
+	var matches []*model.Emoji
+	var words [][]string
+	for page := 0; ; page++ {
+		emojis, appErr := p.API.GetEmojiList(model.EmojiSortByName, page, sweepPageSize)
+		if appErr != nil {
+			p.API.LogWarn("Failed to get the custom emoji to sweep", "page", page, "err", appErr.Error())
+			break
+		}
+		for _, emoji := range emojis {
+			if detections := p.detectEmojiName(emoji.Name); len(detections) > 0 {
+				matches = append(matches, emoji)
+				words = append(words, detectedWords(detections))
+			}
+		}
+		if len(emojis) < sweepPageSize {
+			break
+		}
+	}
+
+	p.API.LogDebug("Custom emoji sweep finished", "matches", len(matches))
+	if len(matches) == 0 || p.getConfiguration().ModeratorUsernames == "" {
+		return
+	}
+
+	header := "#### Custom emoji containing profanity\n\n" +
+		"Delete these emoji in **Main Menu > Custom Emoji**.\n\n" +
+		"| Name | Words | Creator |\n" +
+		"|:-----|:------|:--------|\n"
+	rows := make([]string, 0, len(matches))
+	for i, emoji := range matches {
+		creator := emoji.CreatorId
+		if user, appErr := p.API.GetUser(emoji.CreatorId); appErr == nil {
+			creator = "@" + user.Username
+		}
+		rows = append(rows, fmt.Sprintf("| `%s` | %s | %s |\n", emoji.Name, strings.Join(words[i], ", "), creator))
+	}
+	p.notifyModeratorsReport(header, rows)
+}
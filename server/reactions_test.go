@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+)
+
+func TestReactions(t *testing.T) {
+	newPlugin := func(t *testing.T, config *configuration) (*Plugin, *plugintest.API) {
+		config.FilterReactions = true
+		config.BadWordsList = "fuck,poop,🖕"
+		p, api := newTestPluginWithAPI(t, config)
+		p.botID = "bot-id"
+		return p, api
+	}
+
+	testCases := []struct {
+		name      string
+		emojiName string
+		removed   bool
+	}{
+		{"custom emoji named with a bad word", "fuck_this", true},
+		{"custom emoji with separators", "party-fuck+1", true},
+		{"system emoji matching an emoji term", "fu", true},
+		{"system emoji named like a bad word", "poop", false},
+		{"clean custom emoji", "party_parrot", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, api := newPlugin(t, &configuration{})
+			reaction := &model.Reaction{UserId: "user-id", PostId: "post-id", EmojiName: tc.emojiName}
+			if tc.removed {
+				api.On("RemoveReaction", reaction).Return(nil).Once()
+				api.On("GetDirectChannel", "bot-id", "user-id").Return(&model.Channel{Id: "dm"}, nil).Once()
+				api.On("CreatePost", mock.Anything).Return(&model.Post{}, nil).Once()
+			}
+
+			p.ReactionHasBeenAdded(&plugin.Context{}, reaction)
+
+			api.AssertExpectations(t)
+			if !tc.removed {
+				api.AssertNotCalled(t, "RemoveReaction", mock.Anything)
+			}
+		})
+	}
+
+	t.Run("sweep lists matching custom emoji to moderators", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{ModeratorUsernames: "mod"})
+
+		firstPage := make([]*model.Emoji, sweepPageSize)
+		for i := range firstPage {
+			firstPage[i] = &model.Emoji{Name: "clean", CreatorId: "user-id"}
+		}
+		firstPage[42] = &model.Emoji{Name: "fuck_it", CreatorId: "user-id"}
+
+		api.On("GetEmojiList", model.EmojiSortByName, 0, sweepPageSize).Return(firstPage, nil).Once()
+		api.On("GetEmojiList", model.EmojiSortByName, 1, sweepPageSize).Return([]*model.Emoji{{Name: "fuckface", CreatorId: "user-id"}}, nil).Once()
+		api.On("GetUser", "user-id").Return(&model.User{Username: "alice"}, nil)
+		api.On("GetUserByUsername", "mod").Return(&model.User{Id: "mod-id"}, nil)
+		api.On("GetDirectChannel", "bot-id", "mod-id").Return(&model.Channel{Id: "dm"}, nil)
+		api.On("CreatePost", mock.MatchedBy(func(post *model.Post) bool {
+			return assert.Contains(t, post.Message, "| `fuck_it` | fuck | @alice |") &&
+				assert.NotContains(t, post.Message, "fuckface") && assert.NotContains(t, post.Message, "clean")
+		})).Return(&model.Post{}, nil).Once()
+
+		p.sweepCustomEmoji()
+
+		api.AssertExpectations(t)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		p, api := newPlugin(t, &configuration{})
+		p.configuration.FilterReactions = false
+
+		p.ReactionHasBeenAdded(&plugin.Context{}, &model.Reaction{EmojiName: "fuck_this"})
+		p.sweepCustomEmoji()
+
+		api.AssertNotCalled(t, "RemoveReaction", mock.Anything)
+		api.AssertNotCalled(t, "GetEmojiList", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
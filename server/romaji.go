@@ -0,0 +1,118 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// hiraganaRomaji maps each hiragana to its Hepburn romanization
+var hiraganaRomaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ゔ': "vu",
+}
+
+// smallHiraganaVowels maps the small kana that modify the vowel of the preceding syllable
+var smallHiraganaVowels = map[rune]string{
+	'ゃ': "a", 'ゅ': "u", 'ょ': "o",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+}
+
+// romajiWordRegex finds the Latin words of a lowercased text
+var romajiWordRegex = regexp.MustCompile(`[a-z]+`)
+
+// romajiLongVowels spells long vowels the way they are written without macrons, so that
+// "chikushou", "chikushoo" and "chikushō" share the same canonical form
+var romajiLongVowels = strings.NewReplacer(
+	"ou", "o",
+	"oo", "o",
+	"uu", "u",
+	"aa", "a",
+	"ii", "i",
+)
+
+// hiraganaToRomaji converts a hiragana reading to Hepburn romaji. Other characters are dropped.
+func hiraganaToRomaji(reading string) string {
+	var builder strings.Builder
+	geminate := false
+
+	for _, r := range reading {
+		if r == 'っ' {
+			geminate = true
+			continue
+		}
+
+		if vowel, ok := smallHiraganaVowels[r]; ok {
+			romaji := builder.String()
+			if last, size := utf8.DecodeLastRuneInString(romaji); isLatinVowel(last) {
+				romaji = romaji[:len(romaji)-size]
+			}
+			// きゃ is "kya", but しゃ, ちゃ and じゃ are "sha", "cha" and "ja"
+			if strings.ContainsRune("ゃゅょ", r) &&
+				!strings.HasSuffix(romaji, "sh") && !strings.HasSuffix(romaji, "ch") && !strings.HasSuffix(romaji, "j") {
+				romaji += "y"
+			}
+			builder.Reset()
+			builder.WriteString(romaji + vowel)
+			continue
+		}
+
+		syllable, ok := hiraganaRomaji[r]
+		if !ok {
+			continue
+		}
+		if geminate {
+			// っち is "tchi", every other consonant is doubled
+			if strings.HasPrefix(syllable, "ch") {
+				builder.WriteByte('t')
+			} else if !isLatinVowel(rune(syllable[0])) {
+				builder.WriteByte(syllable[0])
+			}
+			geminate = false
+		}
+		builder.WriteString(syllable)
+	}
+
+	return builder.String()
+}
+
+// canonicalRomaji lowercases romaji and folds the different spellings of long vowels
+func canonicalRomaji(romaji string) string {
+	return romajiLongVowels.Replace(strings.ToLower(removeAccents(romaji)))
+}
+
+// detectRomajiWords matches canonical romaji words against the Latin words of the text
+func detectRomajiWords(text string, romajiWords []string) []detection {
+	var detected []detection
+
+	words := make(map[string]bool, len(romajiWords))
+	for _, word := range romajiWords {
+		words[word] = true
+	}
+
+	normalized := normalizeRunes(text, func(r rune) string { return strings.ToLower(removeAccents(string(r))) })
+	for _, match := range romajiWordRegex.FindAllStringIndex(normalized.text, -1) {
+		if words[canonicalRomaji(normalized.text[match[0]:match[1]])] {
+			start, end := normalized.originalSpan(match[0], match[1])
+			d := newDetection(text, start, end)
+			d.Strategy = "romaji"
+			detected = append(detected, d)
+		}
+	}
+
+	return detected
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	snowballstem "github.com/blevesearch/snowballstem"
+	"github.com/blevesearch/snowballstem/danish"
+	"github.com/blevesearch/snowballstem/dutch"
+	"github.com/blevesearch/snowballstem/english"
+	"github.com/blevesearch/snowballstem/finnish"
+	"github.com/blevesearch/snowballstem/french"
+	"github.com/blevesearch/snowballstem/german"
+	"github.com/blevesearch/snowballstem/hungarian"
+	"github.com/blevesearch/snowballstem/italian"
+	"github.com/blevesearch/snowballstem/norwegian"
+	"github.com/blevesearch/snowballstem/portuguese"
+	"github.com/blevesearch/snowballstem/romanian"
+	"github.com/blevesearch/snowballstem/russian"
+	"github.com/blevesearch/snowballstem/spanish"
+	"github.com/blevesearch/snowballstem/swedish"
+	"github.com/blevesearch/snowballstem/turkish"
+)
+
+// stemmers maps the languages accepted by the StemmingLanguages setting to their Snowball stemmer
+var stemmers = map[string]func(env *snowballstem.Env) bool{
+	"danish":     danish.Stem,
+	"dutch":      dutch.Stem,
+	"english":    english.Stem,
+	"finnish":    finnish.Stem,
+	"french":     french.Stem,
+	"german":     german.Stem,
+	"hungarian":  hungarian.Stem,
+	"italian":    italian.Stem,
+	"norwegian":  norwegian.Stem,
+	"portuguese": portuguese.Stem,
+	"romanian":   romanian.Stem,
+	"russian":    russian.Stem,
+	"spanish":    spanish.Stem,
+	"swedish":    swedish.Stem,
+	"turkish":    turkish.Stem,
+}
+
+// stemmedWordRegex finds the words of a text that are candidates for stemming
+var stemmedWordRegex = regexp.MustCompile(`\p{L}+`)
+
+// stemmedTerms maps each stemming language to the stems of the bad words, and each stem to the
+// bad word it was computed from
+type stemmedTerms map[string]map[string]string
+
+// parseStemmingLanguages splits the StemmingLanguages setting into the languages to stem
+func parseStemmingLanguages(setting string) ([]string, error) {
+	var languages []string
+	for _, language := range splitWordList(setting) {
+		language = strings.ToLower(language)
+		if _, ok := stemmers[language]; !ok {
+			return nil, fmt.Errorf("unsupported stemming language %q", language)
+		}
+		languages = append(languages, language)
+	}
+	return languages, nil
+}
+
+// stem returns the stem of a lowercased word in the given language
+func stem(language, word string) string {
+	env := snowballstem.NewEnv(word)
+	stemmers[language](env)
+	return env.Current()
+}
+
+// isStemmableTerm reports whether a term is a single plain word that can be matched by its stem.
+// Regular expressions, phrases and terms marked with the nostem option are excluded.
+func isStemmableTerm(t term) bool {
+	return !t.hasOption(termOptionNoStem) && !isJapaneseWord(t.Word) && isPlainWord(t.Word)
+}
+
+// termLanguage returns the language a term is stemmed in: the language of its lang option, or
+// the first of the stemming languages. Terms in an unsupported language are not stemmed.
+func termLanguage(t term, languages []string) (string, bool) {
+	language := strings.ToLower(t.optionValue(termOptionLanguage))
+	if language == "" {
+		return languages[0], true
+	}
+	_, ok := stemmers[language]
+	return language, ok
+}
+
+// compileStemmedTerms computes the stem of each stemmable term in the language of the term
+func compileStemmedTerms(terms []term, languages []string) stemmedTerms {
+	if len(languages) == 0 {
+		return nil
+	}
+
+	stems := stemmedTerms{}
+	for _, t := range terms {
+		if !isStemmableTerm(t) {
+			continue
+		}
+		language, ok := termLanguage(t, languages)
+		if !ok {
+			continue
+		}
+		if stems[language] == nil {
+			stems[language] = map[string]string{}
+		}
+		if key := stem(language, strings.ToLower(t.Word)); stems[language][key] == "" {
+			stems[language][key] = t.Word
+		}
+	}
+	return stems
+}
+
+// detectStemmedWords matches every word of the text whose stem is the stem of a bad word in the
+// language of the bad word, e.g. "fucking" and "fucked" for "fuck". Common words are never
+// matched, so that e.g. "gods" does not match "god".
+func (p *Plugin) detectStemmedWords(text string, terms *termSet) []detection {
+	var detected []detection
+
+	stems := terms.stemmedTerms
+	if len(stems) == 0 {
+		return detected
+	}
+
+	for _, match := range stemmedWordRegex.FindAllStringIndex(text, -1) {
+		word := strings.ToLower(text[match[0]:match[1]])
+		if isCommonWord(word) {
+			continue
+		}
+		for language, languageStems := range stems {
+			if termWord, ok := languageStems[stem(language, word)]; ok {
+				d := newDetection(text, match[0], match[1])
+				d.Strategy = "stem"
+				d.Term = termWord
+				detected = append(detected, d)
+				break
+			}
+		}
+	}
+
+	return detected
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestStemming(t *testing.T) {
+	testCases := []struct {
+		name           string
+		wordList       string
+		languages      string
+		input          string
+		expectedOutput string
+	}{
+		{"stemming disabled by default", "fuck", "", "fucking fucked", "fucking fucked"},
+		{"english inflections", "fuck", "english", "fucking fucked Fucks", "******* ****** *****"},
+		{"base word still matches", "fuck", "english", "fuck", "****"},
+		{"spanish inflections", "joder", "spanish", "jodido jodiendo", "****** ********"},
+		{"german inflections", "scheiße", "german", "Scheißen", "********"},
+		{"terms are stemmed in their language", "fuck,joder#lang=spanish", "english, spanish", "fucking jodiendo", "******* ********"},
+		{"terms default to the first language", "fuck,joder", "english, spanish", "fucking jodiendo", "******* jodiendo"},
+		{"unsupported term language", "joder#lang=klingon", "english", "jodiendo joder", "jodiendo *****"},
+		{"nostem opt-out", "ass#nostem", "english", "asses ass", "asses ***"},
+		{"regex terms are not stemmed", "ass(es)?", "english", "assing", "assing"},
+		{"unrelated words are kept", "hell", "english", "hello", "hello"},
+		{"common words are kept", "hell,homo,bloody,God", "english, german, spanish, french", "hello homes bloodied", "hello homes bloodied"},
+		{"plural of a common word is kept", "God", "english", "the gods", "the gods"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPlugin(t, &configuration{CensorCharacter: "*", BadWordsList: tc.wordList, StemmingLanguages: tc.languages})
+
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+
+			t.Logf("Input: %s", tc.input)
+			t.Logf("Output: %s", rpost.Message)
+			t.Logf("Expected: %s", tc.expectedOutput)
+
+			assert.Equal(t, tc.expectedOutput, rpost.Message)
+		})
+	}
+
+	t.Run("unsupported language is rejected", func(t *testing.T) {
+		p := &Plugin{
+			configuration: &configuration{
+				BadWordsList:      "fuck",
+				StemmingLanguages: "klingon",
+			},
+		}
+		assert.Error(t, p.compileWordRegexes(p.getConfiguration().BadWordsList))
+	})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/pluginapi/cluster"
+)
+
+const (
+	// sweepKey identifies the scheduled sweep over the profiles of existing users and the custom emoji
+	sweepKey = "Sweep"
+	// sweepPageSize is the number of users or emoji fetched at a time by the sweep
+	sweepPageSize = 100
+)
+
+// sweep checks the profiles of existing users and the names of the custom emoji, as enabled
+func (p *Plugin) sweep() {
+	p.sweepProfiles()
+	p.sweepCustomEmoji()
+}
+
+// scheduleSweep schedules the sweep at the configured interval, replacing the sweep scheduled before
+func (p *Plugin) scheduleSweep(config *configuration) error {
+	if p.sweepJob != nil {
+		if err := p.sweepJob.Close(); err != nil {
+			return err
+		}
+		p.sweepJob = nil
+	}
+
+	if !(config.FilterProfiles || config.FilterReactions) || config.SweepIntervalHours <= 0 {
+		return nil
+	}
+
+	interval := time.Duration(config.SweepIntervalHours) * time.Hour
+	job, err := cluster.Schedule(p.API, sweepKey, cluster.MakeWaitForRoundedInterval(interval), p.sweep)
+	if err != nil {
+		return err
+	}
+	p.sweepJob = job
+	return nil
+}
+
+// OnDeactivate stops the scheduled sweep
+func (p *Plugin) OnDeactivate() error {
+	if p.sweepJob != nil {
+		return p.sweepJob.Close()
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// termOptionSeparator separates a bad words list entry from its per-term options,
+// e.g. "chutiya#hindi".
+const termOptionSeparator = "#"
+
+const (
+	// termOptionHindi routes a Latin term through the Hindi handler so that it also matches
+	// Devanagari and alternative romanized spellings.
+	termOptionHindi = "hindi"
+
+	// termOptionLemma makes a Japanese term also match the conjugated forms of its dictionary
+	// form instead of its surface form only.
+	termOptionLemma = "lemma"
+
+	// termOptionReading makes a Japanese term also match its reading written in kana.
+	termOptionReading = "reading"
+
+	// termOptionRomaji makes a Japanese term also match its reading written in Hepburn romaji.
+	termOptionRomaji = "romaji"
+
+	// termOptionExact only matches a Japanese term that is exactly one token.
+	termOptionExact = "exact"
+
+	// termOptionSequence matches a Japanese term that starts and ends on token boundaries.
+	termOptionSequence = "sequence"
+
+	// termOptionCompound marks a Japanese compound term that may also match inside longer words.
+	termOptionCompound = "compound"
+
+	// termOptionNoStem excludes a term from stemming, for stems that collide with innocent words.
+	termOptionNoStem = "nostem"
+
+	// termOptionNoFuzzy excludes a term from fuzzy matching.
+	termOptionNoFuzzy = "nofuzzy"
+
+	// termOptionPhonetic makes a term also match words that sound the same, e.g. "phuck".
+	termOptionPhonetic = "phonetic"
+
+	// termOptionCategory assigns a term to a category, e.g. "category=slurs", whose detections can
+	// be censored in their own style.
+	termOptionCategory = "category"
+
+	// termOptionReplace replaces a term with a euphemism instead of censoring it, e.g.
+	// "fuck#replace=fudge".
+	termOptionReplace = "replace"
+
+	// termOptionLanguage selects the language a term is stemmed in, e.g. "joder#lang=spanish",
+	// instead of the first of the stemming languages
+	termOptionLanguage = "lang"
+)
+
+// termOptionValueSeparator separates the name of a per-term option from its value
+const termOptionValueSeparator = "="
+
+// term is a single bad words list entry together with its per-term options
+type term struct {
+	Word    string
+	Options []string
+}
+
+// termFlagOptions are the per-term options that are set by their name alone
+var termFlagOptions = map[string]bool{
+	termOptionHindi:    true,
+	termOptionLemma:    true,
+	termOptionReading:  true,
+	termOptionRomaji:   true,
+	termOptionExact:    true,
+	termOptionSequence: true,
+	termOptionCompound: true,
+	termOptionNoStem:   true,
+	termOptionNoFuzzy:  true,
+	termOptionPhonetic: true,
+}
+
+// termValueOptions are the per-term options that take a value, e.g. "category=slurs"
+var termValueOptions = map[string]bool{
+	termOptionCategory: true,
+	termOptionReplace:  true,
+	termOptionLanguage: true,
+}
+
+// isTermOption checks if the text after a separator is a known per-term option
+func isTermOption(option string) bool {
+	option = strings.ToLower(strings.TrimSpace(option))
+	if name, value, ok := strings.Cut(option, termOptionValueSeparator); ok {
+		return termValueOptions[strings.TrimSpace(name)] && strings.TrimSpace(value) != ""
+	}
+	return termFlagOptions[option]
+}
+
+// parseTerm splits a bad words list entry into the word and its per-term options. Entries whose
+// text after a separator is not a known option, such as "c#", are taken literally. Option names
+// are lowercased, while the values keep their case, e.g. "replace=Darn".
+func parseTerm(entry string) term {
+	parts := strings.Split(entry, termOptionSeparator)
+	for _, option := range parts[1:] {
+		if !isTermOption(option) {
+			return term{Word: strings.TrimSpace(entry)}
+		}
+	}
+
+	t := term{Word: strings.TrimSpace(parts[0])}
+	for _, option := range parts[1:] {
+		option = strings.TrimSpace(option)
+		if name, value, ok := strings.Cut(option, termOptionValueSeparator); ok {
+			option = strings.ToLower(strings.TrimSpace(name)) + termOptionValueSeparator + strings.TrimSpace(value)
+		} else {
+			option = strings.ToLower(option)
+		}
+		t.Options = append(t.Options, option)
+	}
+
+	return t
+}
+
+// parseTerms parses every entry of a split word list, dropping entries without a word
+func parseTerms(entries []string) []term {
+	var terms []term
+	for _, entry := range entries {
+		t := parseTerm(entry)
+		if t.Word != "" {
+			terms = append(terms, t)
+		}
+	}
+
+	return terms
+}
+
+// hasOption reports whether the term was configured with the given option
+func (t term) hasOption(option string) bool {
+	for _, o := range t.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// optionValue returns the value of the term option with the given name, e.g. "slurs" for
+// "category=slurs", or an empty string if the term was not configured with it
+func (t term) optionValue(name string) string {
+	for _, o := range t.Options {
+		if value, ok := strings.CutPrefix(o, name+termOptionValueSeparator); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// category returns the lowercased category the term was assigned to, or an empty string
+func (t term) category() string {
+	return strings.ToLower(t.optionValue(termOptionCategory))
+}
+
+// replacement returns the euphemism the term is replaced with, or an empty string
+func (t term) replacement() string {
+	return t.optionValue(termOptionReplace)
+}
+
+// termWords returns the words of the given terms without their options
+func termWords(terms []term) []string {
+	words := make([]string, 0, len(terms))
+	for _, t := range terms {
+		words = append(words, t.Word)
+	}
+	return words
+}
+
+// isPlainWord checks if a word is a single word made of letters only, as opposed to a regular
+// expression or a phrase
+func isPlainWord(word string) bool {
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// termIndex finds the term a detected word matched, to look up its per-term options
+type termIndex struct {
+	words    map[string]term
+	patterns []termPattern
+}
+
+// termPattern is a term written as a regular expression or phrase, matched against whole words
+type termPattern struct {
+	regex *regexp.Regexp
+	term  term
+}
+
+// termKey normalizes a word the way the scanned text is normalized before matching
+func termKey(word string) string {
+	if isJapaneseWord(word) {
+		return normalizeJapanese(word).text
+	}
+	return strings.ToLower(removeAccents(word))
+}
+
+// compileTermIndex indexes the terms by their normalized word, and compiles the terms that are
+// not plain words into anchored patterns. The first of several terms with the same word wins.
+func compileTermIndex(terms []term) *termIndex {
+	index := &termIndex{words: map[string]term{}}
+	for _, t := range terms {
+		key := termKey(t.Word)
+		if _, ok := index.words[key]; ok {
+			continue
+		}
+		index.words[key] = t
+
+		if isPlainWord(t.Word) || isJapaneseWord(t.Word) || isEmojiWord(t.Word) {
+			continue
+		}
+		if regex, err := regexp.Compile(`(?i)^(?:` + t.Word + `)$`); err == nil {
+			index.patterns = append(index.patterns, termPattern{regex: regex, term: t})
+		}
+	}
+	return index
+}
+
+// lookup finds the term a word matched
+func (index *termIndex) lookup(word string) (term, bool) {
+	if index == nil {
+		return term{}, false
+	}
+	key := termKey(word)
+	if t, ok := index.words[key]; ok {
+		return t, true
+	}
+	for _, pattern := range index.patterns {
+		if pattern.regex.MatchString(key) {
+			return pattern.term, true
+		}
+	}
+	return term{}, false
+}
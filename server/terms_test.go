@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+)
+
+func TestParseTerm(t *testing.T) {
+	testCases := []struct {
+		entry    string
+		expected term
+	}{
+		{"chutiya#hindi", term{Word: "chutiya", Options: []string{"hindi"}}},
+		{"fuck#category=mild#nostem", term{Word: "fuck", Options: []string{"category=mild", "nostem"}}},
+		{"damn#Replace=Darn#NOSTEM", term{Word: "damn", Options: []string{"replace=Darn", "nostem"}}},
+		{"c#", term{Word: "c#"}},
+		{"f#ck", term{Word: "f#ck"}},
+		{"f#ck#nostem", term{Word: "f#ck#nostem"}},
+		{"fuck#category=", term{Word: "fuck#category="}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.entry, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseTerm(tc.entry))
+		})
+	}
+}
+
+func TestLiteralSeparatorTerms(t *testing.T) {
+	p := Plugin{
+		configuration: &configuration{
+			CensorCharacter: "*",
+			BadWordsList:    "c#,f#ck",
+		},
+	}
+	if err := p.compileWordRegexes(p.getConfiguration().BadWordsList); err != nil {
+		t.Fatalf("Failed to compile word regexes: %v", err)
+	}
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"c# is nice", "c# is nice"},
+		{"what the f#ck", "what the ****"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			rpost, s := p.MessageWillBePosted(&plugin.Context{}, &model.Post{Message: tc.input})
+			assert.Empty(t, s)
+			assert.Equal(t, tc.expected, rpost.Message)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"strings"
+	"unicode/utf8"
 )
 
 // splitWordList splits a word list by ASCII commas and cleans up whitespace
@@ -29,3 +30,65 @@ func normalizeWordListCommas(wordList string) string {
 	normalized = strings.ReplaceAll(normalized, "，", ",") // Full-width comma (U+FF0C)
 	return normalized
 }
+
+// separatorsToSpaces replaces the given single byte separators of a name with spaces, so that the
+// words of e.g. "fuck_you-now.txt" are matched on their own. The spans of the result are the spans
+// of the name.
+func separatorsToSpaces(name, separators string) string {
+	return strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && strings.ContainsRune(separators, r) {
+			return ' '
+		}
+		return r
+	}, name)
+}
+
+// normalizedText is a normalized copy of a text that remembers which span of the original text
+// each of its bytes was produced from, so that matches found in the normalized text can be
+// censored in the original one
+type normalizedText struct {
+	text   string
+	starts []int
+	ends   []int
+}
+
+// normalizedTextBuilder builds a normalizedText one original span at a time
+type normalizedTextBuilder struct {
+	builder strings.Builder
+	starts  []int
+	ends    []int
+}
+
+// add appends the normalized form of the original span [start, end)
+func (b *normalizedTextBuilder) add(normalized string, start, end int) {
+	b.builder.WriteString(normalized)
+	for i := 0; i < len(normalized); i++ {
+		b.starts = append(b.starts, start)
+		b.ends = append(b.ends, end)
+	}
+}
+
+// build returns the normalized text built so far
+func (b *normalizedTextBuilder) build() normalizedText {
+	return normalizedText{text: b.builder.String(), starts: b.starts, ends: b.ends}
+}
+
+// normalizeRunes normalizes a text rune by rune
+func normalizeRunes(text string, normalize func(r rune) string) normalizedText {
+	var builder normalizedTextBuilder
+	for i, r := range text {
+		builder.add(normalize(r), i, i+utf8.RuneLen(r))
+	}
+	return builder.build()
+}
+
+// originalSpan maps the span [start, end) of the normalized text onto the original text. The
+// normalized text may be reordered, e.g. reversed, so the whole span is looked at.
+func (n normalizedText) originalSpan(start, end int) (originalStart, originalEnd int) {
+	originalStart, originalEnd = n.starts[start], n.ends[start]
+	for i := start + 1; i < end; i++ {
+		originalStart = min(originalStart, n.starts[i])
+		originalEnd = max(originalEnd, n.ends[i])
+	}
+	return originalStart, originalEnd
+}